@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/harakeishi/gopose/internal/file"
+	"github.com/harakeishi/gopose/internal/generator"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
 )
 
 var (
@@ -11,13 +20,24 @@ var (
 	allFiles   bool
 )
 
+// cleanTarget は clean で削除するファイルです。
+type cleanTarget struct {
+	path string
+	// edited は生成後に手動で編集されていることを表します（--force を指定しない限り削除しません）。
+	edited bool
+	// backup はバックアップであることを表します（チェックサムのファイルもあわせて削除します）。
+	backup bool
+}
+
 // cleanCmd はcleanコマンドを表します。
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "生成されたoverride.ymlファイルを削除",
-	Long: `gopose により生成された docker-compose.override.yml ファイルを削除します。
+	Long: `gopose により生成された docker-compose.override.yml ファイルと、
+テンプレートから生成したファイル（設定の file.templates）を削除します。
 
-バックアップファイルが存在する場合、それらも削除対象になります。`,
+生成後に手動で編集されたファイルは --force を指定しない限り削除しません。
+--all を指定すると、バックアップ（file.backup_dir）も削除対象になります。`,
 	Example: `  # 基本的なクリーンアップ
   gopose clean
 
@@ -37,16 +57,125 @@ var cleanCmd = &cobra.Command{
 
 		logger.Info(ctx, "gopose clean コマンドを開始しています")
 
-		// TODO: 実際の実装をここに追加
-		fmt.Println("生成されたファイルのクリーンアップを実行中...")
-		fmt.Println("現在は実装中です。")
+		composeFiles, err := resolveComposeFiles(ctx, logger)
+		if err != nil {
+			return err
+		}
+		autoLoadOverride := composeAutoLoads(composeFiles)
+		overrideGenerator := generator.NewOverrideGeneratorImpl(logger)
+		_, overridePath, err := resolveOverrideTarget(ctx, logger, overrideGenerator, composeFiles, autoLoadOverride)
+		if err != nil {
+			return err
+		}
+
+		targets, err := collectCleanTargets(ctx, logger, cfg, overridePath)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			fmt.Println("削除するファイルはありません")
+			return nil
+		}
+
+		fmt.Println("次のファイルを削除します:")
+		for _, target := range targets {
+			if target.edited && !forceClean {
+				fmt.Printf("  %s（生成後に編集されているため残します。--force で削除できます）\n", target.path)
+			} else {
+				fmt.Printf("  %s\n", target.path)
+			}
+		}
+
+		if !forceClean {
+			fmt.Print("削除しますか？ [y/N]: ")
+			answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Println("中止しました")
+				return nil
+			}
+		}
+
+		backupManager := file.NewBackupManagerImpl(cfg.GetFile().BackupDir, logger)
+		removed := 0
+		for _, target := range targets {
+			if target.edited && !forceClean {
+				continue
+			}
+			if target.backup {
+				if err := backupManager.RemoveBackup(ctx, target.path); err != nil {
+					return err
+				}
+			} else if err := os.Remove(target.path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("%s の削除に失敗しました: %w", target.path, err)
+			}
+			removed++
+			logger.Debug(ctx, "ファイルを削除しました", types.Field{Key: "file_path", Value: target.path})
+		}
+		fmt.Printf("%d 件のファイルを削除しました\n", removed)
 
 		return nil
 	},
 }
 
+// collectCleanTargets はoverrideのメタデータから削除するファイルを集めます。
+// バックアップは --all の場合のみ対象にします。
+func collectCleanTargets(ctx context.Context, log logger.Logger, cfg types.Config, overridePath string) ([]cleanTarget, error) {
+	content, err := os.ReadFile(overridePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s の読み込みに失敗しました: %w", overridePath, err)
+	}
+
+	unchanged, err := generator.VerifyChecksum(content)
+	if err != nil {
+		unchanged = false
+	}
+	targets := []cleanTarget{{path: overridePath, edited: !unchanged}}
+	paths := []string{overridePath}
+
+	metadata, err := generator.NewMetadataManagerImpl(appVersion, log).ReadMetadata(ctx, overridePath)
+	if err != nil {
+		log.Warn(ctx, fmt.Sprintf("%s のメタデータを読み取れないため、テンプレートから生成したファイルは削除しません", overridePath),
+			types.Field{Key: "error", Value: err.Error()})
+	}
+	if metadata != nil {
+		for _, generated := range metadata.GeneratedFiles {
+			exists, edited, err := generator.CheckGeneratedFile(generated.Path, metadata.GeneratedFiles)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				targets = append(targets, cleanTarget{path: generated.Path, edited: edited})
+			}
+			paths = append(paths, generated.Path)
+		}
+	}
+
+	if allFiles {
+		backupManager := file.NewBackupManagerImpl(cfg.GetFile().BackupDir, log)
+		for _, path := range paths {
+			backups, err := backupManager.ListBackups(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+			for _, backup := range backups {
+				targets = append(targets, cleanTarget{path: backup.Path, backup: true})
+			}
+			// バックアップが無効な場合に --force で退避したファイル
+			if _, err := os.Stat(path + ".bak"); err == nil {
+				targets = append(targets, cleanTarget{path: path + ".bak"})
+			}
+		}
+	}
+
+	return targets, nil
+}
+
 func init() {
 	// cleanコマンド固有のフラグを定義
-	cleanCmd.Flags().BoolVar(&forceClean, "force", false, "確認なしで強制削除")
-	cleanCmd.Flags().BoolVar(&allFiles, "all", false, "すべての関連ファイルを削除")
+	cleanCmd.Flags().BoolVar(&forceClean, "force", false, "確認なしで強制削除（生成後に編集されたファイルも削除）")
+	cleanCmd.Flags().BoolVar(&allFiles, "all", false, "バックアップを含むすべての関連ファイルを削除")
+	cleanCmd.Flags().StringArrayVarP(&filePaths, "file", "f", nil, "Docker Composeファイルのパス（複数指定可）")
 }
@@ -18,6 +18,9 @@ var (
 	cfgFile string
 	verbose bool
 	detail  bool
+
+	// appVersion は gopose のバージョンです（overrideのメタデータに記録されます）。
+	appVersion = "dev"
 )
 
 // rootCmd はルートコマンドを表します。
@@ -44,6 +47,12 @@ var rootCmd = &cobra.Command{
   gopose up --port-range 9000-9999`,
 }
 
+// SetVersion はビルド時に埋め込まれたバージョン情報を設定します。
+func SetVersion(version, commit, date string) {
+	appVersion = version
+	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built at: %s)", version, commit, date)
+}
+
 // Execute はコマンドを実行します。
 func Execute(ctx context.Context) error {
 	return rootCmd.ExecuteContext(ctx)
@@ -61,6 +70,7 @@ func init() {
 	rootCmd.AddCommand(upCmd)
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(validateCmd)
 }
 
 // initConfig は設定を初期化します。
@@ -100,6 +110,9 @@ func getConfig() types.Config {
 		return cfg
 	}
 
+	// 既知のポートは組み込みの値に設定ファイルの値を追加する（同じイメージは設定ファイルを優先）
+	cfg.HostNetwork.WellKnownPorts = append(config.DefaultWellKnownPorts(), cfg.HostNetwork.WellKnownPorts...)
+
 	// verboseフラグが設定されている場合
 	if verbose {
 		cfg.Log.Level = "debug"
@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/harakeishi/gopose/internal/generator"
+	"github.com/harakeishi/gopose/internal/parser"
+	"github.com/harakeishi/gopose/internal/scanner"
+	"github.com/harakeishi/gopose/pkg/types"
 )
 
 var (
@@ -15,8 +24,13 @@ var (
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "現在の状態確認",
-	Long: `Docker Composeプロジェクトの現在の状態、ポート使用状況、
-および gopose による変更の状況を確認します。`,
+	Long: `gopose が生成したoverrideが現在のComposeファイル・環境変数・ポートの使用状況に対して
+有効かを確認します。
+
+- fresh: 入力が変わっておらず、そのまま使えます
+- stale: Composeファイル・.env・環境変数・プロファイルが変更されています
+- invalid: 存在しないサービスの参照、割り当てたポートの使用、新しい衝突などがあります
+- missing: overrideがありません`,
 	Example: `  # 基本的な状態確認
   gopose status
 
@@ -34,18 +48,123 @@ var statusCmd = &cobra.Command{
 			return fmt.Errorf("ロガーの初期化に失敗しました: %w", err)
 		}
 
+		if outputFormat != "text" && outputFormat != "json" && outputFormat != "yaml" {
+			return fmt.Errorf("未対応の出力形式です: %s (text, json, yaml のいずれかを指定してください)", outputFormat)
+		}
+
 		logger.Info(ctx, "gopose status コマンドを開始しています")
 
-		// TODO: 実際の実装をここに追加
-		fmt.Println("現在の状態を確認中...")
-		fmt.Println("現在は実装中です。")
+		// up と同じくワークツリー名をプロジェクト名として使用
+		if composeProjectName == "" && os.Getenv("COMPOSE_PROJECT_NAME") == "" {
+			if pn, err := detectWorktreeProjectName(); err == nil && pn != "" {
+				composeProjectName = pn
+			}
+		}
+
+		composeFiles, err := resolveComposeFiles(ctx, logger)
+		if err != nil {
+			return err
+		}
+		autoLoadOverride := composeAutoLoads(composeFiles)
+		overrideGenerator := generator.NewOverrideGeneratorImpl(logger)
+		composeFiles, overridePath, err := resolveOverrideTarget(ctx, logger, overrideGenerator, composeFiles, autoLoadOverride)
+		if err != nil {
+			return err
+		}
+
+		composeParser, err := newComposeParser(cmd, cfg, logger)
+		if err != nil {
+			return err
+		}
+		config, err := composeParser.ParseComposeFiles(ctx, composeFiles)
+		if err != nil {
+			return fmt.Errorf("Docker Composeファイルの解析に失敗: %w", err)
+		}
+		profiles := resolveProfiles()
+		config, _ = parser.FilterActiveServices(config, profiles, nil)
+
+		portDetector := scanner.NewNetstatPortDetector(logger)
+		networkDetector := scanner.NewDockerNetworkDetector(logger)
+		containerDetector := scanner.NewDockerContainerDetector(logger)
+		volumeDetector := scanner.NewDockerVolumeDetector(logger)
+		unifiedDetector := scanner.NewUnifiedConflictDetectorWithHostNetworkConfig(portDetector, networkDetector, containerDetector, volumeDetector, cfg.GetHostNetwork(), logger)
+		conflictInfo, err := unifiedDetector.DetectConflicts(ctx, config, composeProjectName)
+		if err != nil {
+			return fmt.Errorf("衝突検知に失敗: %w", err)
+		}
+
+		fingerprint, err := generator.ComputeFingerprint(config, config.FilePaths, composeEnvFiles(cmd), profiles, templateInputs(resolveOverrideTemplate(cfg), resolveConfigTemplates(cfg, composeFiles))...)
+		if err != nil {
+			return fmt.Errorf("入力の指紋の計算に失敗: %w", err)
+		}
+		format, err := resolveOverrideFormat(cfg)
+		if err != nil {
+			return err
+		}
+		listSyntax, _, _ := detectListSyntax(ctx, logger)
+		listSyntax = generator.ListSyntaxForFormat(listSyntax, effectiveOverrideFormat(format, overridePath))
+		status, err := checkOverrideFreshness(ctx, logger, overridePath, format, listSyntax, config, fingerprint, conflictInfo, portDetector, containerDetector)
+		if err != nil {
+			return fmt.Errorf("overrideの状態の確認に失敗: %w", err)
+		}
+		if !detailed {
+			status.Metadata = nil
+		}
 
+		switch outputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(status); err != nil {
+				return fmt.Errorf("状態の出力に失敗しました: %w", err)
+			}
+		case "yaml":
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(status); err != nil {
+				return fmt.Errorf("状態の出力に失敗しました: %w", err)
+			}
+		default:
+			printOverrideStatus(status, conflictInfo)
+		}
 		return nil
 	},
 }
 
+// printOverrideStatus はoverrideの状態をテキストで出力します。
+func printOverrideStatus(status *generator.OverrideStatus, conflictInfo *types.UnifiedConflictInfo) {
+	fmt.Printf("%s: %s\n", status.Path, status.State)
+	for _, reason := range status.Reasons {
+		fmt.Printf("  - %s\n", reason)
+	}
+
+	switch status.State {
+	case generator.OverrideStateMissing:
+		if conflictInfo.HasConflicts() {
+			fmt.Println("衝突があります。gopose up でoverrideを生成してください")
+		}
+	case generator.OverrideStateStale, generator.OverrideStateInvalid:
+		fmt.Println("gopose up で再生成してください")
+	}
+
+	if status.Metadata == nil {
+		return
+	}
+	fmt.Printf("生成: %s（gopose %s）\n", status.Metadata.GeneratedAt.Format(time.RFC3339), status.Metadata.Version)
+	for _, resolution := range status.Metadata.Resolutions {
+		fmt.Printf("  %s: %d → %d\n", resolution.Service, resolution.OriginalPort, resolution.ResolvedPort)
+	}
+	for _, resolution := range status.Metadata.NetworkResolutions {
+		fmt.Printf("  %s: %s → %s\n", resolution.Network, resolution.OriginalSubnet, resolution.ResolvedSubnet)
+	}
+}
+
 func init() {
 	// statusコマンド固有のフラグを定義
 	statusCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "出力形式 (text, json, yaml)")
 	statusCmd.Flags().BoolVar(&detailed, "detailed", false, "詳細情報を表示")
+	statusCmd.Flags().StringArrayVarP(&filePaths, "file", "f", nil, "Docker Composeファイルのパス（複数指定可、指定順にマージ）")
+	statusCmd.Flags().StringVarP(&composeProjectName, "project-name", "p", "", "Docker Composeプロジェクト名")
+	statusCmd.Flags().StringVar(&overrideTemplate, "template", "", "overrideにマージするテンプレート（設定の file.override_template より優先）")
+	statusCmd.Flags().StringArrayVar(&composeProfiles, "profile", nil, "有効にするプロファイル（複数指定可、未指定時はCOMPOSE_PROFILES）")
 }
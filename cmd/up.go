@@ -9,21 +9,37 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/harakeishi/gopose/internal/file"
 	"github.com/harakeishi/gopose/internal/generator"
+	"github.com/harakeishi/gopose/internal/logger"
 	"github.com/harakeishi/gopose/internal/parser"
 	"github.com/harakeishi/gopose/internal/scanner"
 	"github.com/harakeishi/gopose/pkg/types"
 	"github.com/spf13/cobra"
 )
 
+const (
+	// defaultOverrideFile はComposeが自動で読み込むoverrideファイル名です。
+	defaultOverrideFile = "docker-compose.override.yml"
+	// separateOverrideFile は手書きのoverrideが存在する場合に使用する出力ファイル名です。
+	separateOverrideFile = "docker-compose.gopose.yml"
+	// stdoutOutput は -o に指定するとoverrideを標準出力に書き込む値です。
+	stdoutOutput = "-"
+)
+
 var (
-	filePath           string
+	filePaths          []string
 	portRange          string
 	dryRun             bool
 	strategy           string
 	outputFile         string
 	skipComposeUp      bool
 	composeProjectName string
+	composeProfiles    []string
+	noEnvRewrite       bool
+	forceWrite         bool
+	overrideTemplate   string
+	overrideFormat     string
 )
 
 // parsePortRange はポート範囲文字列を解析します。
@@ -110,25 +126,332 @@ func detectWorktreeProjectName() (string, error) {
 	return topLevelBase, nil
 }
 
-// runDockerCompose はdocker composeコマンドを実行します。
-func runDockerCompose(ctx *cobra.Command, composeFile, outputFile string, extraArgs []string) error {
-	args := []string{"compose"}
+// checkOverrideFreshness は既存のoverrideが現在の入力・ポートの使用状況に対して最新かを判定します。
+// このプロジェクトのコンテナが公開しているポートは、使用中でも問題としません。
+func checkOverrideFreshness(ctx context.Context, log logger.Logger, path string, format generator.OverrideFormat, listSyntax generator.ListSyntax, config *types.ComposeConfig, fingerprint string, conflictInfo *types.UnifiedConflictInfo, portDetector scanner.PortDetector, containerDetector *scanner.DockerContainerDetector) (*generator.OverrideStatus, error) {
+	usedPorts := make(map[int]bool)
+	if ports, err := portDetector.DetectUsedPorts(ctx); err == nil {
+		for _, port := range ports {
+			usedPorts[port] = true
+		}
+	} else {
+		log.Debug(ctx, "使用中のポートを取得できませんでした", types.Field{Key: "error", Value: err.Error()})
+	}
+
+	projectPorts := make(map[int]bool)
+	if project := scanner.ProjectName(composeProjectName, config); project != "" {
+		if ports, err := containerDetector.DetectProjectPorts(ctx, project); err == nil {
+			for _, port := range ports {
+				projectPorts[port] = true
+			}
+		} else {
+			log.Debug(ctx, "プロジェクトのコンテナのポートを取得できませんでした", types.Field{Key: "error", Value: err.Error()})
+		}
+	}
+
+	metadataManager := generator.NewMetadataManagerImpl(appVersion, log)
+	return metadataManager.CheckFreshness(ctx, path, generator.FreshnessCheck{
+		Config:       config,
+		Fingerprint:  fingerprint,
+		Conflicts:    conflictInfo,
+		UsedPorts:    usedPorts,
+		ProjectPorts: projectPorts,
+		Format:       format,
+		ListSyntax:   listSyntax,
+	})
+}
+
+// resolveOverrideFormat はoverrideの出力形式を返します（--format、設定の file.override_format の順）。
+// どちらも指定されていない場合は空を返し、出力先の拡張子から決めます。
+func resolveOverrideFormat(cfg types.Config) (generator.OverrideFormat, error) {
+	format := overrideFormat
+	if format == "" {
+		format = cfg.GetFile().OverrideFormat
+	}
+	switch strings.ToLower(format) {
+	case "":
+		return "", nil
+	case "yaml", "yml":
+		return generator.OverrideFormatYAML, nil
+	case "json":
+		return generator.OverrideFormatJSON, nil
+	default:
+		return "", fmt.Errorf("未対応の出力形式です: %s (yaml または json を指定してください)", format)
+	}
+}
+
+// effectiveOverrideFormat はoverrideの出力形式を返します（指定が無い場合は出力先の拡張子、標準出力はYAML）。
+func effectiveOverrideFormat(format generator.OverrideFormat, outputPath string) generator.OverrideFormat {
+	switch {
+	case format != "":
+		return format
+	case outputPath == stdoutOutput:
+		return generator.OverrideFormatYAML
+	default:
+		return generator.OverrideFormatFromPath(outputPath)
+	}
+}
+
+// detectListSyntax はインストールされているComposeのバージョンから、overrideでリストを置き換える記法を決めます。
+// バージョンを取得できない場合（docker が無いなど）は !override を使います。
+// !reset にも対応していない古いバージョンの場合は、記法を空にしてエラーを返します。
+func detectListSyntax(ctx context.Context, log logger.Logger) (generator.ListSyntax, scanner.ComposeVersion, error) {
+	version, err := scanner.NewDockerComposeVersionDetector(log).DetectVersion(ctx)
+	if err != nil {
+		log.Debug(ctx, "Docker Composeのバージョンを取得できないため !override を使用します",
+			types.Field{Key: "error", Value: err.Error()})
+		return generator.ListSyntaxOverride, version, nil
+	}
+	syntax, err := generator.ListSyntaxForComposeVersion(version)
+	return syntax, version, err
+}
+
+// resolveOverrideTemplate はoverrideにマージするテンプレートのパスを返します（--template、設定の順）。
+func resolveOverrideTemplate(cfg types.Config) string {
+	if overrideTemplate != "" {
+		return overrideTemplate
+	}
+	return cfg.GetFile().OverrideTemplate
+}
+
+// resolveConfigTemplates は設定の file.templates を、Composeファイルのディレクトリを基準に解決して返します。
+func resolveConfigTemplates(cfg types.Config, composeFiles []string) []types.TemplateFile {
+	baseDir := "."
+	if len(composeFiles) > 0 && composeFiles[0] != "-" {
+		baseDir = filepath.Dir(composeFiles[0])
+	}
+	return generator.ResolveTemplateFiles(cfg.GetFile().Templates, baseDir)
+}
+
+// templateInputs は入力の指紋に含めるテンプレートのパスを返します。
+func templateInputs(templatePath string, configTemplates []types.TemplateFile) []string {
+	var paths []string
+	if templatePath != "" {
+		paths = append(paths, templatePath)
+	}
+	for _, template := range configTemplates {
+		paths = append(paths, template.Template)
+	}
+	return paths
+}
+
+// unusedGeneratedFiles は前回生成し、今回は生成しなかったファイルを返します。
+func unusedGeneratedFiles(previous, current []types.GeneratedFile) []types.GeneratedFile {
+	generated := make(map[string]bool)
+	for _, generatedFile := range current {
+		generated[generatedFile.Path] = true
+	}
+	var unused []types.GeneratedFile
+	for _, generatedFile := range previous {
+		if !generated[generatedFile.Path] {
+			unused = append(unused, generatedFile)
+		}
+	}
+	return unused
+}
+
+// resolveComposeFiles は解析対象のComposeファイルを決定します。
+// 優先順位は -f フラグ、COMPOSE_FILE 環境変数、カレントディレクトリからの自動検出の順です。
+func resolveComposeFiles(ctx context.Context, log logger.Logger) ([]string, error) {
+	if len(filePaths) > 0 {
+		return filePaths, nil
+	}
+
+	if envFiles := composeFilesFromEnv(); len(envFiles) > 0 {
+		log.Info(ctx, "COMPOSE_FILE環境変数からComposeファイルを取得",
+			types.Field{Key: "files", Value: envFiles})
+		return envFiles, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリの取得に失敗: %w", err)
+	}
+
+	detector := parser.NewComposeFileDetectorImpl(log)
+	detectedFile, err := detector.GetDefaultComposeFile(ctx, wd)
+	if err != nil {
+		return nil, fmt.Errorf("Docker Composeファイルの自動検出に失敗: %w", err)
+	}
+	log.Info(ctx, "Docker Composeファイルを自動検出", types.Field{Key: "file", Value: detectedFile})
+
+	return []string{detectedFile}, nil
+}
+
+// composeAutoLoads は -f を付けずに実行したComposeが composeFiles と既定のoverrideを自動で読み込むかを返します。
+// JSONのComposeファイルは gopose では自動検出しますが、Compose は読み込まないため -f での指定が必要です。
+func composeAutoLoads(composeFiles []string) bool {
+	if len(filePaths) > 0 || os.Getenv("COMPOSE_FILE") != "" {
+		return false
+	}
+	for _, composeFile := range composeFiles {
+		if format, ok := parser.FormatFromPath(composeFile); ok && format == parser.ComposeFormatJSON {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveOverrideTarget はoverrideファイルの出力先を決定します。
+// 手書きのoverrideファイルは上書きせず、Composeが自動で読み込む場合はポート検出の対象に含めます。
+func resolveOverrideTarget(ctx context.Context, log logger.Logger, overrideGenerator *generator.OverrideGeneratorImpl, composeFiles []string, autoLoadOverride bool) ([]string, string, error) {
+	output := outputFile
+	explicitOutput := output != ""
+	if output == stdoutOutput {
+		return composeFiles, output, nil
+	}
+	if output == "" {
+		output = defaultOverrideFile
+	}
+
+	// -f 未指定時はComposeが既存のoverrideを自動マージするため、そのポート設定も考慮する
+	if autoLoadOverride {
+		detector := parser.NewComposeFileDetectorImpl(log)
+		if existing, found := detector.DetectOverrideFile(ctx, filepath.Dir(composeFiles[0])); found {
+			generated, err := overrideGenerator.IsGeneratedByGopose(ctx, existing)
+			if err != nil {
+				return nil, "", err
+			}
+			if !generated {
+				log.Info(ctx, "手書きのoverrideファイルを検出しました。衝突検知の対象に含めます",
+					types.Field{Key: "file", Value: existing})
+				composeFiles = append(composeFiles, existing)
+			}
+		}
+	}
+
+	// 出力先に手書きのファイルが存在する場合は上書きしない
+	for {
+		if _, err := os.Stat(output); err != nil {
+			break
+		}
+		generated, err := overrideGenerator.IsGeneratedByGopose(ctx, output)
+		if err != nil {
+			return nil, "", err
+		}
+		if generated {
+			break
+		}
+		if explicitOutput || output == separateOverrideFile {
+			return nil, "", fmt.Errorf("出力先 %s はgoposeが生成したファイルではないため上書きしません。-o で別の出力先を指定してください", output)
+		}
+
+		log.Warn(ctx, "手書きのoverrideファイルが存在するため、別ファイルに出力します",
+			types.Field{Key: "existing_file", Value: output},
+			types.Field{Key: "output_file", Value: separateOverrideFile})
+		output = separateOverrideFile
+	}
+
+	return composeFiles, output, nil
+}
+
+// composeFilesFromEnv はCOMPOSE_FILE環境変数をCOMPOSE_PATH_SEPARATORで分割して返します。
+func composeFilesFromEnv() []string {
+	value := os.Getenv("COMPOSE_FILE")
+	if value == "" {
+		return nil
+	}
+
+	separator := os.Getenv("COMPOSE_PATH_SEPARATOR")
+	if separator == "" {
+		separator = string(os.PathListSeparator)
+	}
+
+	var files []string
+	for _, file := range strings.Split(value, separator) {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// resolveProfiles は有効なプロファイルを決定します。
+// --profile が指定されていない場合はCOMPOSE_PROFILES環境変数（カンマ区切り）を使用します。
+func resolveProfiles() []string {
+	if len(composeProfiles) > 0 {
+		return composeProfiles
+	}
+
+	var profiles []string
+	for _, profile := range strings.Split(os.Getenv("COMPOSE_PROFILES"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// composeEnvFiles は --env-file で指定されたファイルを返します（指定が無い、またはフラグが無いコマンドでは nil）。
+func composeEnvFiles(cmd *cobra.Command) []string {
+	envFiles, err := cmd.Flags().GetStringSlice("env-file")
+	if err != nil || len(envFiles) == 0 {
+		return nil
+	}
+	return envFiles
+}
+
+// newComposeParser は設定（file.parser）に応じたComposeファイルの解析バックエンドを返します。
+func newComposeParser(cmd *cobra.Command, cfg types.Config, logger logger.Logger) (parser.ComposeFilesParser, error) {
+	switch backend := parser.ParserBackend(cfg.GetFile().Parser); backend {
+	case "", parser.ParserBackendYAML:
+		return parser.NewYamlComposeParserWithEnvFiles(logger, composeEnvFiles(cmd)), nil
+	case parser.ParserBackendDocker:
+		return parser.NewDockerComposeParser(logger, parser.DockerComposeOptions{
+			ProjectName: composeProjectName,
+			EnvFiles:    composeEnvFiles(cmd),
+			Profiles:    resolveProfiles(),
+		}), nil
+	default:
+		return nil, fmt.Errorf("未対応のパーサーです: %s (yaml または docker を指定してください)", backend)
+	}
+}
+
+// explicitServiceNames はupコマンドの引数からサービス名を抽出します。
+func explicitServiceNames(args []string) []string {
+	var services []string
+	for _, arg := range args {
+		if arg != "" && !strings.HasPrefix(arg, "-") {
+			services = append(services, arg)
+		}
+	}
+	return services
+}
 
-	// compose fileオプションを追加（デフォルトファイル名でない場合のみ）
-	if composeFile != "" && composeFile != "docker-compose.yml" {
+// composeProfileArgs はdocker composeに渡す --profile 引数を組み立てます。
+func composeProfileArgs(profiles []string) []string {
+	var args []string
+	for _, profile := range profiles {
+		args = append(args, "--profile", profile)
+	}
+	return args
+}
+
+// composeFileArgs はdocker composeに渡す -f 引数を組み立てます。
+// 生成したoverrideファイルは常にファイルチェーンの最後に配置します。
+func composeFileArgs(composeFiles []string, outputFile string) []string {
+	var args []string
+	for _, composeFile := range composeFiles {
 		args = append(args, "-f", composeFile)
-	} else {
-		// デフォルトファイルは明示的に指定
-		args = append(args, "-f", "docker-compose.yml")
 	}
 
-	// override fileが存在する場合は追加
 	if outputFile != "" {
 		if _, err := os.Stat(outputFile); err == nil {
 			args = append(args, "-f", outputFile)
 		}
 	}
 
+	return args
+}
+
+// runDockerCompose はdocker composeコマンドを実行します。
+func runDockerCompose(ctx *cobra.Command, composeFiles []string, outputFile string, extraArgs []string) error {
+	args := []string{"compose"}
+
+	// compose fileオプションを追加（overrideファイルは最後）
+	args = append(args, composeFileArgs(composeFiles, outputFile)...)
+	args = append(args, composeProfileArgs(resolveProfiles())...)
+
 	// プロジェクト名が指定されている場合
 	if composeProjectName != "" {
 		args = append(args, "-p", composeProjectName)
@@ -212,11 +535,11 @@ func runDockerCompose(ctx *cobra.Command, composeFile, outputFile string, extraA
 }
 
 // stopExistingContainers は既存のコンテナを停止・削除します。
-func stopExistingContainers(ctx context.Context, composeFile string) error {
+func stopExistingContainers(ctx context.Context, composeFiles []string) error {
 	args := []string{"compose"}
 
 	// compose fileオプションを追加
-	if composeFile != "" {
+	for _, composeFile := range composeFiles {
 		args = append(args, "-f", composeFile)
 	}
 
@@ -236,6 +559,17 @@ func stopExistingContainers(ctx context.Context, composeFile string) error {
 	return cmd.Run()
 }
 
+// composeHostPorts はCompose内で公開されているホストポート（範囲指定を展開したもの）を返します。
+func composeHostPorts(config *types.ComposeConfig) []int {
+	var ports []int
+	for _, service := range config.Services {
+		for _, mapping := range service.Ports {
+			ports = append(ports, mapping.HostPorts()...)
+		}
+	}
+	return ports
+}
+
 // detectNetworkSubnets collects all subnets configured in Compose file
 func getComposeSubnets(config *types.ComposeConfig) map[string]string {
 	result := make(map[string]string)
@@ -350,6 +684,9 @@ var upCmd = &cobra.Command{
   # 特定のファイルを指定
   gopose up -f custom-compose.yml
 
+  # 複数のファイルを指定（指定順にマージ）
+  gopose up -f compose.yml -f compose.dev.yml
+
   # ポート範囲を指定
   gopose up --port-range 9000-9999
 
@@ -388,59 +725,153 @@ var upCmd = &cobra.Command{
 
 		logger.Info(ctx, "ポート衝突解決を開始",
 			types.Field{Key: "dry_run", Value: dryRun},
-			types.Field{Key: "compose_file", Value: filePath},
+			types.Field{Key: "compose_files", Value: filePaths},
 			types.Field{Key: "output_file", Value: outputFile},
 			types.Field{Key: "project_name", Value: composeProjectName},
 			types.Field{Key: "strategy", Value: strategy},
 			types.Field{Key: "port_range", Value: fmt.Sprintf("%d-%d", portConfig.Range.Start, portConfig.Range.End)})
 
-		// Docker Composeファイルの自動検出（指定されていない場合）
-		if filePath == "" || filePath == "docker-compose.yml" {
-			wd, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("作業ディレクトリの取得に失敗: %w", err)
-			}
+		// Docker Composeファイルの決定（-f, COMPOSE_FILE, 自動検出）
+		composeFiles, err := resolveComposeFiles(ctx, logger)
+		if err != nil {
+			return err
+		}
+		autoLoadOverride := composeAutoLoads(composeFiles)
 
-			detector := parser.NewComposeFileDetectorImpl(logger)
-			detectedFile, err := detector.GetDefaultComposeFile(ctx, wd)
-			if err != nil {
-				return fmt.Errorf("Docker Composeファイルの自動検出に失敗: %w", err)
-			}
-			filePath = detectedFile
-			logger.Info(ctx, "Docker Composeファイルを自動検出", types.Field{Key: "file", Value: filePath})
+		// 出力先と出力形式の決定（手書きのoverrideファイルは上書きしない）
+		overrideGenerator := generator.NewOverrideGeneratorImpl(logger)
+		composeFiles, outputFile, err = resolveOverrideTarget(ctx, logger, overrideGenerator, composeFiles, autoLoadOverride)
+		if err != nil {
+			return err
 		}
+		format, err := resolveOverrideFormat(cfg)
+		if err != nil {
+			return err
+		}
+
+		// Composeのバージョンと出力形式からリストの置き換え方を決める（対応していない場合はoverrideを生成する時点でエラーにする）
+		listSyntax, composeVersion, listSyntaxErr := detectListSyntax(ctx, logger)
+		listSyntax = generator.ListSyntaxForFormat(listSyntax, effectiveOverrideFormat(format, outputFile))
 
-		// Docker Composeファイルの解析
-		yamlParser := parser.NewYamlComposeParser(logger)
-		config, err := yamlParser.ParseComposeFile(ctx, filePath)
+		// Docker Composeファイルの解析（複数ファイルは指定順にマージ）
+		composeParser, err := newComposeParser(cmd, cfg, logger)
+		if err != nil {
+			return err
+		}
+		config, err := composeParser.ParseComposeFiles(ctx, composeFiles)
 		if err != nil {
 			return fmt.Errorf("Docker Composeファイルの解析に失敗: %w", err)
 		}
 
+		// プロファイルにより起動されないサービスを検知対象から除外
+		profiles := resolveProfiles()
+		config, inactiveServices := parser.FilterActiveServices(config, profiles, explicitServiceNames(args))
+		if len(inactiveServices) > 0 {
+			logger.Info(ctx, "無効なプロファイルのサービスを対象外にしました",
+				types.Field{Key: "profiles", Value: profiles},
+				types.Field{Key: "inactive_services", Value: inactiveServices})
+		}
+
 		// 統一的な衝突検知の実行
 		portDetector := scanner.NewNetstatPortDetector(logger)
 		portAllocator := scanner.NewPortAllocatorImpl(portDetector, logger)
 		networkDetector := scanner.NewDockerNetworkDetector(logger)
-		unifiedDetector := scanner.NewUnifiedConflictDetectorImpl(portDetector, networkDetector, logger)
+		containerDetector := scanner.NewDockerContainerDetector(logger)
+		volumeDetector := scanner.NewDockerVolumeDetector(logger)
+		unifiedDetector := scanner.NewUnifiedConflictDetectorWithHostNetworkConfig(portDetector, networkDetector, containerDetector, volumeDetector, cfg.GetHostNetwork(), logger)
 
 		conflictInfo, err := unifiedDetector.DetectConflicts(ctx, config, composeProjectName)
 		if err != nil {
 			return fmt.Errorf("衝突検知に失敗: %w", err)
 		}
 
-		// 衝突がない場合
-		if !conflictInfo.HasConflicts() {
-			logger.Info(ctx, "衝突は検出されませんでした")
+		// network_mode: host の衝突はoverrideで解決できないため、対処方法とあわせて報告のみ行う
+		for _, conflict := range conflictInfo.HostNetworkConflicts {
+			logger.Warn(ctx, conflict.Description,
+				types.Field{Key: "service", Value: conflict.Service},
+				types.Field{Key: "port", Value: conflict.Port},
+				types.Field{Key: "type", Value: conflict.Type})
+		}
+		if conflictInfo.HasHostNetworkConflicts() {
+			logger.Warn(ctx, "対処方法: "+conflictInfo.HostNetworkConflicts[0].Guidance)
+		}
+
+		// external / バインドマウントの共有はoverrideで分離できないため報告のみ行う
+		sharedVolumes := false
+		for _, conflict := range conflictInfo.VolumeConflicts {
+			if conflict.IsResolvable() {
+				continue
+			}
+			sharedVolumes = true
+			logger.Warn(ctx, conflict.Description,
+				types.Field{Key: "services", Value: conflict.Services},
+				types.Field{Key: "kind", Value: conflict.Kind})
+		}
+		if sharedVolumes {
+			logger.Warn(ctx, "対処方法: ワークツリーごとに分離したい場合は名前付きボリュームまたはプロジェクト内の相対パスを使用してください")
+		}
+
+		// 既存のoverrideが最新であれば再生成しない
+		templatePath := resolveOverrideTemplate(cfg)
+		configTemplates := resolveConfigTemplates(cfg, composeFiles)
+		fingerprint, err := generator.ComputeFingerprint(config, config.FilePaths, composeEnvFiles(cmd), profiles, templateInputs(templatePath, configTemplates)...)
+		if outputFile == stdoutOutput && len(configTemplates) > 0 {
+			// 標準出力ではoverrideをファイルに残さないため、生成したファイルを clean で削除できるよう記録する場所が無い
+			logger.Warn(ctx, fmt.Sprintf("標準出力に出力する場合、設定ファイルのテンプレート（file.templates）の %d 件は生成されません。生成する場合は -o で出力先を指定してください", len(configTemplates)))
+			configTemplates = nil
+		}
+		if err != nil {
+			return fmt.Errorf("入力の指紋の計算に失敗: %w", err)
+		}
+		overrideState := generator.OverrideStateMissing
+		if outputFile != stdoutOutput {
+			status, err := checkOverrideFreshness(ctx, logger, outputFile, format, listSyntax, config, fingerprint, conflictInfo, portDetector, containerDetector)
+			if err != nil {
+				return fmt.Errorf("既存のoverrideの確認に失敗: %w", err)
+			}
+			overrideState = status.State
+			switch status.State {
+			case generator.OverrideStateFresh:
+				// ポートを付け替えたoverrideは、元のリストを置き換えられないComposeでは使えない
+				if listSyntaxErr != nil && status.Metadata != nil && len(status.Metadata.Resolutions) > 0 {
+					cmd.SilenceUsage = true
+					return listSyntaxErr
+				}
+				logger.Info(ctx, fmt.Sprintf("%s は最新のため再生成しません", outputFile))
+				return nil
+			case generator.OverrideStateStale, generator.OverrideStateInvalid:
+				logger.Info(ctx, fmt.Sprintf("%s は %s です: %s", outputFile, status.State, strings.Join(status.Reasons, "; ")),
+					types.Field{Key: "state", Value: status.State},
+					types.Field{Key: "reasons", Value: status.Reasons})
+			}
+		}
+
+		// 衝突がない場合（設定ファイルのテンプレートは元のポートで描画し、clean で削除できるようoverrideのメタデータに記録する）
+		if !conflictInfo.HasConflicts() && len(configTemplates) == 0 {
+			if overrideState == generator.OverrideStateStale || overrideState == generator.OverrideStateInvalid {
+				logger.Warn(ctx, fmt.Sprintf("%s は不要になりましたが、Composeが読み込むため残っています。gopose clean で削除してください", outputFile))
+			}
+			if conflictInfo.HasHostNetworkConflicts() || sharedVolumes {
+				logger.Info(ctx, "overrideで解決できる衝突はありませんでした")
+			} else {
+				logger.Info(ctx, "衝突は検出されませんでした")
+			}
 			if skipComposeUp {
 				logger.Warn(ctx, "--skip-compose-upオプションは不要になりました。デフォルトでdocker compose upは実行されません。")
 			}
 			return nil
 		}
 
+		if !conflictInfo.HasConflicts() {
+			logger.Info(ctx, "衝突は検出されませんでした。設定ファイルのテンプレートを元のポートで描画します")
+		}
+
 		// 衝突結果の表示
 		logger.Info(ctx, "衝突検知完了",
 			types.Field{Key: "port_conflicts", Value: len(conflictInfo.PortConflicts)},
-			types.Field{Key: "network_conflicts", Value: len(conflictInfo.NetworkConflicts)})
+			types.Field{Key: "network_conflicts", Value: len(conflictInfo.NetworkConflicts)},
+			types.Field{Key: "container_name_conflicts", Value: len(conflictInfo.ContainerNameConflicts)},
+			types.Field{Key: "volume_conflicts", Value: len(conflictInfo.VolumeConflicts)})
 
 		// 解決戦略の決定
 		resolutionStrategy := types.ResolutionStrategyAutoIncrement
@@ -453,19 +884,61 @@ var upCmd = &cobra.Command{
 			resolutionStrategy = types.ResolutionStrategyUserDefined
 		}
 
+		// Compose内で公開済みのホストポートには再割り当てしない
+		portConfig.Reserved = append(portConfig.Reserved, composeHostPorts(config)...)
+
 		// 統一的な衝突解決
 		unifiedGenerator := generator.NewUnifiedOverrideGeneratorImpl(portAllocator, logger)
 		if err := unifiedGenerator.ResolveConflicts(ctx, conflictInfo, resolutionStrategy, portConfig); err != nil {
 			return fmt.Errorf("衝突解決に失敗: %w", err)
 		}
 
+		// ポート変更の影響範囲（変更したサービスに依存するサービス）を求める
+		// depends_on の循環は影響範囲の算出には支障がないため警告のみ
+		dependencyGraph, err := parser.BuildDependencyGraph(config)
+		if err != nil {
+			logger.Warn(ctx, err.Error())
+		}
+
 		// 解決結果の表示
-		for _, conflict := range conflictInfo.PortConflicts {
+		for i := range conflictInfo.PortConflicts {
+			conflict := &conflictInfo.PortConflicts[i]
+			if conflict.Resolution == nil {
+				continue
+			}
+			conflict.Resolution.AffectedServices = dependencyGraph.Dependents(conflict.ServiceName)
+
+			logger.Info(ctx, "ポート解決",
+				types.Field{Key: "service", Value: conflict.ServiceName},
+				types.Field{Key: "from", Value: conflict.Port},
+				types.Field{Key: "to", Value: conflict.Resolution.ResolvedPort},
+				types.Field{Key: "reason", Value: conflict.Resolution.Reason},
+				types.Field{Key: "affected_services", Value: conflict.Resolution.AffectedServices})
+			if len(conflict.Resolution.AffectedServices) > 0 {
+				logger.Info(ctx, fmt.Sprintf("影響範囲: %s のホストポート %d→%d の変更は %s に影響します（依存元）",
+					conflict.ServiceName, conflict.Port, conflict.Resolution.ResolvedPort,
+					strings.Join(conflict.Resolution.AffectedServices, ", ")))
+			} else {
+				logger.Info(ctx, fmt.Sprintf("影響範囲: %s に依存するサービスはありません", conflict.ServiceName))
+			}
+		}
+
+		for _, conflict := range conflictInfo.ContainerNameConflicts {
 			if conflict.Resolution != nil {
-				logger.Info(ctx, "ポート解決",
-					types.Field{Key: "service", Value: conflict.ServiceName},
-					types.Field{Key: "from", Value: conflict.Port},
-					types.Field{Key: "to", Value: conflict.Resolution.ResolvedPort},
+				logger.Info(ctx, "コンテナ名解決",
+					types.Field{Key: "service", Value: conflict.Service},
+					types.Field{Key: "from", Value: conflict.ContainerName},
+					types.Field{Key: "to", Value: conflict.Resolution.ResolvedName},
+					types.Field{Key: "reason", Value: conflict.Resolution.Reason})
+			}
+		}
+
+		for _, conflict := range conflictInfo.VolumeConflicts {
+			if conflict.Resolution != nil {
+				logger.Info(ctx, "ボリューム解決",
+					types.Field{Key: "volume", Value: conflict.VolumeName},
+					types.Field{Key: "from", Value: conflict.ActualName},
+					types.Field{Key: "to", Value: conflict.Resolution.ResolvedName},
 					types.Field{Key: "reason", Value: conflict.Resolution.Reason})
 			}
 		}
@@ -486,6 +959,24 @@ var upCmd = &cobra.Command{
 			return fmt.Errorf("Overrideファイルの生成に失敗: %w", err)
 		}
 
+		// 付け替えたホストポートを参照している環境変数（env_file を含む）の書き換え
+		if !noEnvRewrite {
+			for _, rewrite := range unifiedGenerator.RewriteEnvironment(ctx, config, conflictInfo, override) {
+				logger.Info(ctx, fmt.Sprintf("環境変数の書き換え: %s の %s: %s → %s（定義元: %s）",
+					rewrite.Service, rewrite.Variable, rewrite.From, rewrite.To, rewrite.Source),
+					types.Field{Key: "service", Value: rewrite.Service},
+					types.Field{Key: "variable", Value: rewrite.Variable},
+					types.Field{Key: "source", Value: rewrite.Source})
+			}
+		}
+
+		// 元のリストを置き換えられないComposeでは、ポートが追記されて衝突したままになるため生成しない
+		if generator.NeedsListReplacement(override) && listSyntaxErr != nil {
+			cmd.SilenceUsage = true
+			return listSyntaxErr
+		}
+		override.Metadata.ListSyntax = string(listSyntax)
+
 		// プロジェクト名をoverrideに設定（Docker Composeコマンドの統一のため）
 		if composeProjectName != "" {
 			override.Name = composeProjectName
@@ -493,23 +984,124 @@ var upCmd = &cobra.Command{
 				types.Field{Key: "project_name", Value: composeProjectName})
 		}
 
+		// 生成情報（バージョン・元ファイルのハッシュなど）をメタデータに記録
+		metadataManager := generator.NewMetadataManagerImpl(appVersion, logger)
+		override.Metadata.Fingerprint = fingerprint
+		if err := metadataManager.Populate(ctx, &override.Metadata, scanner.ProjectName(composeProjectName, config), config.FilePaths); err != nil {
+			return fmt.Errorf("メタデータの作成に失敗: %w", err)
+		}
+
+		// テンプレートの設定を生成内容にマージする
+		templateGenerator := generator.NewOverrideTemplateGeneratorImpl(logger)
+		if templatePath != "" {
+			content, err := templateGenerator.RenderTemplate(ctx, templatePath, generator.NewGenerationData(config, override))
+			if err != nil {
+				return fmt.Errorf("テンプレートの描画に失敗: %w", err)
+			}
+			override.TemplateContent = content
+		}
+
+		// 設定ファイルのテンプレートを描画し、clean で削除できるようメタデータに記録する（書き込みはoverrideの後）
+		renderedFiles, err := templateGenerator.RenderFiles(ctx, configTemplates, generator.NewGenerationData(config, override))
+		if err != nil {
+			return fmt.Errorf("テンプレートの描画に失敗: %w", err)
+		}
+
+		// !reset の場合は、元のリストを取り除くファイルをoverrideより先に読み込むよう別に生成する
+		var resetFile string
+		if listSyntax == generator.ListSyntaxReset && generator.NeedsListReplacement(override) {
+			reason := fmt.Sprintf("Docker Compose %s は !override に対応していない", composeVersion)
+			if effectiveOverrideFormat(format, outputFile) == generator.OverrideFormatJSON {
+				reason = "JSON形式には !override を書けない"
+			}
+			if outputFile == stdoutOutput {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%sため !reset のファイルを別に生成する必要があり、標準出力には出力できません。-o で出力先を指定してください", reason)
+			}
+			resetFile = generator.ResetFilePath(outputFile)
+			content, err := overrideGenerator.RenderResetFile(ctx, override)
+			if err != nil {
+				return fmt.Errorf("!reset のファイルの生成に失敗: %w", err)
+			}
+			renderedFiles = append(renderedFiles, generator.RenderedFile{Output: resetFile, Content: content})
+			logger.Warn(ctx, fmt.Sprintf("%sため、%s の !reset で元のリストを取り除いてから %s で追加します", reason, resetFile, outputFile))
+		}
+
+		override.Metadata.GeneratedFiles = nil
+		for _, rendered := range renderedFiles {
+			override.Metadata.GeneratedFiles = append(override.Metadata.GeneratedFiles, rendered.Record())
+		}
+
 		// Override.ymlの妥当性検証
-		overrideGenerator := generator.NewOverrideGeneratorImpl(logger)
 		if err := overrideGenerator.ValidateOverride(ctx, override); err != nil {
 			return fmt.Errorf("Overrideファイルの検証に失敗: %w", err)
 		}
 
-		// 出力ファイル名の決定
-		if outputFile == "" {
-			outputFile = "docker-compose.override.yml"
+		// ドライランモードでない場合のみファイル書き込み
+		if !dryRun && outputFile == stdoutOutput {
+			// パイプラインで使えるよう標準出力に書き込む（ログは標準エラー出力）
+			if format == "" {
+				format = generator.OverrideFormatYAML
+			}
+			if err := overrideGenerator.WriteOverride(ctx, override, os.Stdout, format); err != nil {
+				return fmt.Errorf("Overrideの出力に失敗: %w", err)
+			}
+			return nil
+		}
+
+		// Composeが自動で読み込まない場合の実行コマンド（overrideのヘッダーにも書き込む）
+		var composeCommand string
+		if !autoLoadOverride || outputFile != defaultOverrideFile || resetFile != "" {
+			// 明示的にファイルを指定した場合、Composeはoverrideファイルを自動で読み込まない
+			// !reset のファイルは元のファイルの後、overrideの前に指定する
+			files := composeFiles
+			if resetFile != "" {
+				files = append(append([]string{}, composeFiles...), resetFile)
+			}
+			composeArgs := append([]string{"docker", "compose"}, composeFileArgs(files, "")...)
+			composeArgs = append(composeArgs, "-f", outputFile)
+			composeArgs = append(composeArgs, composeProfileArgs(profiles)...)
+			composeCommand = strings.Join(append(composeArgs, "up"), " ")
 		}
 
-		// ドライランモードでない場合のみファイル書き込み
 		if !dryRun {
-			// Override.ymlファイルの書き込み
-			if err := overrideGenerator.WriteOverrideFile(ctx, override, outputFile); err != nil {
+			// Override.ymlファイルの書き込み（既存のファイルは設定に応じてバックアップする）
+			writeOptions := generator.WriteOptions{Force: forceWrite, Format: format, ComposeCommand: composeCommand}
+			if fileConfig := cfg.GetFile(); fileConfig.BackupEnabled {
+				writeOptions.Backup = file.NewBackupManagerImpl(fileConfig.BackupDir, logger)
+				writeOptions.BackupRetention = fileConfig.BackupRetention
+			}
+
+			// 前回生成したファイルは上書きできる。それ以外が出力先にあればoverrideを書き込む前に中止する
+			var previousFiles []types.GeneratedFile
+			if previous, err := metadataManager.ReadMetadata(ctx, outputFile); err == nil && previous != nil {
+				previousFiles = previous.GeneratedFiles
+			}
+			if err := templateGenerator.CheckRenderedFiles(ctx, renderedFiles, previousFiles, forceWrite); err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("テンプレートの出力先に書き込めません: %w", err)
+			}
+
+			if err := overrideGenerator.WriteOverrideFile(ctx, override, outputFile, writeOptions); err != nil {
+				cmd.SilenceUsage = true
 				return fmt.Errorf("Overrideファイルの書き込みに失敗: %w", err)
 			}
+			if err := templateGenerator.WriteRenderedFiles(ctx, renderedFiles, previousFiles, writeOptions); err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("テンプレートの出力に失敗: %w", err)
+			}
+			for _, unused := range unusedGeneratedFiles(previousFiles, override.Metadata.GeneratedFiles) {
+				// テンプレートを使わずに生成したファイル（!reset のファイル）は、編集されていなければ削除する
+				if unused.Template == "" {
+					if exists, edited, err := generator.CheckGeneratedFile(unused.Path, previousFiles); err == nil && exists && !edited {
+						if err := os.Remove(unused.Path); err == nil {
+							logger.Info(ctx, fmt.Sprintf("%s は不要になったため削除しました", unused.Path))
+							continue
+						}
+					}
+				}
+				logger.Warn(ctx, fmt.Sprintf("%s は設定から外れたため更新されません。不要であれば削除してください", unused.Path))
+			}
 
 			logger.Info(ctx, "Override.ymlファイルが生成されました",
 				types.Field{Key: "output_file", Value: outputFile})
@@ -525,6 +1117,9 @@ var upCmd = &cobra.Command{
 		// デフォルトではDocker Composeを実行しない
 		if !dryRun {
 			logger.Info(ctx, "override.ymlの生成が完了しました。docker compose upを実行する場合は、手動で実行してください。")
+			if composeCommand != "" {
+				logger.Info(ctx, fmt.Sprintf("実行コマンド: %s", composeCommand))
+			}
 		}
 
 		return nil
@@ -535,13 +1130,18 @@ func init() {
 	// gopose固有のフラグを定義
 	upCmd.Flags().StringVar(&portRange, "port-range", "", "利用するポート範囲 (例: 8000-9999)")
 	upCmd.Flags().StringVar(&strategy, "strategy", "auto", "解決戦略 (auto, range, user)")
-	upCmd.Flags().StringVarP(&outputFile, "output", "o", "", "出力ファイル名 (デフォルト: docker-compose.override.yml)")
+	upCmd.Flags().StringVarP(&outputFile, "output", "o", "", "出力ファイル名 (デフォルト: docker-compose.override.yml、手書きのoverrideが存在する場合は docker-compose.gopose.yml、- で標準出力)")
 	upCmd.Flags().BoolVar(&dryRun, "dry-run", false, "ドライラン（override.yml生成のみ、Docker Composeは実行しない）")
 	upCmd.Flags().BoolVar(&skipComposeUp, "skip-compose-up", false, "[非推奨] このオプションは不要になりました。デフォルトでdocker compose upは実行されません。")
+	upCmd.Flags().StringVar(&overrideFormat, "format", "", "overrideの出力形式 (yaml, json。デフォルト: 設定の file.override_format、未指定時は出力先の拡張子)")
+	upCmd.Flags().StringVar(&overrideTemplate, "template", "", "生成したoverrideにマージするテンプレート（設定の file.override_template より優先）")
+	upCmd.Flags().BoolVar(&forceWrite, "force", false, "生成後に手動で編集されたoverrideも上書きする（編集内容はバックアップされる）")
+	upCmd.Flags().BoolVar(&noEnvRewrite, "no-env-rewrite", false, "付け替えたホストポートを参照している環境変数を書き換えない")
 
 	// Docker Composeオプションもサポート（透過的に渡される）
-	upCmd.Flags().StringVarP(&filePath, "file", "f", "docker-compose.yml", "Docker Composeファイルのパス")
+	upCmd.Flags().StringArrayVarP(&filePaths, "file", "f", nil, "Docker Composeファイルのパス（複数指定可、指定順にマージ、- で標準入力）")
 	upCmd.Flags().StringVarP(&composeProjectName, "project-name", "p", "", "Docker Composeプロジェクト名")
+	upCmd.Flags().StringArrayVar(&composeProfiles, "profile", nil, "有効にするプロファイル（複数指定可、未指定時はCOMPOSE_PROFILES）")
 	upCmd.Flags().BoolP("detach", "d", false, "Detached mode: バックグラウンドでサービスを実行")
 	upCmd.Flags().Bool("build", false, "サービス起動前にイメージをビルド")
 	upCmd.Flags().Bool("force-recreate", false, "設定が変更されていなくてもコンテナを再作成")
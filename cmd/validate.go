@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harakeishi/gopose/internal/parser"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+var validateOutput string
+
+// validationReport は validate コマンドのJSON出力です。
+type validationReport struct {
+	Valid  bool                     `json:"valid"`
+	Files  []string                 `json:"files"`
+	Issues []parser.ValidationIssue `json:"issues"`
+}
+
+// validateCmd はvalidateコマンドを表します。
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Docker Composeファイルの妥当性検証",
+	Long: `Docker Composeファイルを解析し、以下を検証します。
+
+- ポート番号が 1〜65535 の範囲内であること
+- 同じホストポートに複数のサービスが公開していないこと
+- サブネットが解析でき、固定IP・ゲートウェイがサブネット内にあること
+- 未定義のネットワーク・ボリューム・サービスを参照していないこと
+- depends_on が循環していないこと
+
+問題が見つかった場合は終了コード 1 で終了します。`,
+	Example: `  # カレントディレクトリのComposeファイルを検証
+  gopose validate
+
+  # ファイルを指定してJSON形式で出力
+  gopose validate -f compose.yml --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		cfg := getConfig()
+
+		logger, err := getLogger(cfg)
+		if err != nil {
+			return fmt.Errorf("ロガーの初期化に失敗しました: %w", err)
+		}
+
+		if validateOutput != "text" && validateOutput != "json" {
+			return fmt.Errorf("未対応の出力形式です: %s (text または json を指定してください)", validateOutput)
+		}
+
+		composeFiles, err := resolveComposeFiles(ctx, logger)
+		if err != nil {
+			return err
+		}
+
+		composeParser, err := newComposeParser(cmd, cfg, logger)
+		if err != nil {
+			return err
+		}
+		config, err := composeParser.ParseComposeFiles(ctx, composeFiles)
+		if err != nil {
+			return fmt.Errorf("Docker Composeファイルの解析に失敗: %w", err)
+		}
+
+		validator := parser.NewComposeValidatorImpl(logger)
+		validationErr := validator.ValidateConfig(ctx, config)
+		issues := parser.ValidationIssues(validationErr)
+		if validationErr != nil && issues == nil {
+			return fmt.Errorf("検証に失敗しました: %w", validationErr)
+		}
+
+		if validateOutput == "json" {
+			report := validationReport{
+				Valid:  len(issues) == 0,
+				Files:  composeFiles,
+				Issues: issues,
+			}
+			if report.Issues == nil {
+				report.Issues = []parser.ValidationIssue{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return fmt.Errorf("検証結果の出力に失敗しました: %w", err)
+			}
+		} else {
+			for _, issue := range issues {
+				fmt.Printf("%s: %s\n", issueLabel(issue.Severity), issue)
+			}
+			if len(issues) == 0 {
+				fmt.Println("問題は見つかりませんでした")
+			}
+		}
+
+		if len(issues) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d件の問題が見つかりました", len(issues))
+		}
+		return nil
+	},
+}
+
+// issueLabel はテキスト出力で問題の先頭に付けるラベルを返します。
+func issueLabel(severity types.Severity) string {
+	switch severity {
+	case types.SeverityWarning:
+		return "WARNING"
+	case types.SeverityInfo:
+		return "INFO"
+	default:
+		return "ERROR"
+	}
+}
+
+func init() {
+	validateCmd.Flags().StringArrayVarP(&filePaths, "file", "f", nil, "Docker Composeファイルのパス（複数指定可、指定順にマージ、- で標準入力）")
+	validateCmd.Flags().StringVarP(&composeProjectName, "project-name", "p", "", "Docker Composeプロジェクト名")
+	validateCmd.Flags().StringVarP(&validateOutput, "output", "o", "text", "出力形式 (text, json)")
+}
@@ -18,10 +18,12 @@ func DefaultConfig() *types.AppConfig {
 			ExcludePrivileged: true,
 		},
 		File: types.FileConfig{
-			ComposeFile:   "docker-compose.yml",
-			OverrideFile:  "docker-compose.override.yml",
-			BackupEnabled: true,
-			BackupDir:     ".gopose/backups",
+			ComposeFile:     "docker-compose.yml",
+			OverrideFile:    "docker-compose.override.yml",
+			BackupEnabled:   true,
+			BackupDir:       ".gopose/backups",
+			BackupRetention: 7 * 24 * time.Hour,
+			Parser:          "yaml",
 		},
 		Watcher: types.WatcherConfig{
 			Interval:      5 * time.Second,
@@ -37,6 +39,7 @@ func DefaultConfig() *types.AppConfig {
 			MaxAge:   30,
 			Compress: true,
 		},
+		HostNetwork: DefaultHostNetworkConfig(),
 	}
 }
 
@@ -55,10 +58,12 @@ func DefaultPortConfig() types.PortConfig {
 // DefaultFileConfig はデフォルトのファイル設定を返します。
 func DefaultFileConfig() types.FileConfig {
 	return types.FileConfig{
-		ComposeFile:   "docker-compose.yml",
-		OverrideFile:  "docker-compose.override.yml",
-		BackupEnabled: true,
-		BackupDir:     ".gopose/backups",
+		ComposeFile:     "docker-compose.yml",
+		OverrideFile:    "docker-compose.override.yml",
+		BackupEnabled:   true,
+		BackupDir:       ".gopose/backups",
+		BackupRetention: 7 * 24 * time.Hour,
+		Parser:          "yaml",
 	}
 }
 
@@ -84,6 +89,29 @@ func DefaultLogConfig() types.LogConfig {
 	}
 }
 
+// DefaultHostNetworkConfig はデフォルトのホストネットワーク検知設定を返します。
+// 既知のポートは設定ファイルの内容を読み込んだ後に DefaultWellKnownPorts を先頭に加えます
+// （リストは設定ファイルの内容で要素ごとに上書きされるため、デフォルトには含めません）。
+func DefaultHostNetworkConfig() types.HostNetworkConfig {
+	return types.HostNetworkConfig{}
+}
+
+// DefaultWellKnownPorts は組み込みの既知のポートを返します。設定ファイルの同じイメージの指定が優先されます。
+func DefaultWellKnownPorts() []types.WellKnownPort {
+	return []types.WellKnownPort{
+		{Image: "postgres", Ports: []int{5432}},
+		{Image: "mysql", Ports: []int{3306}},
+		{Image: "mariadb", Ports: []int{3306}},
+		{Image: "redis", Ports: []int{6379}},
+		{Image: "mongo", Ports: []int{27017}},
+		{Image: "memcached", Ports: []int{11211}},
+		{Image: "rabbitmq", Ports: []int{5672}},
+		{Image: "elasticsearch", Ports: []int{9200, 9300}},
+		{Image: "nginx", Ports: []int{80}},
+		{Image: "httpd", Ports: []int{80}},
+	}
+}
+
 // RecommendedConfigs は推奨設定のバリエーションを提供します。
 
 // DevelopmentConfig は開発環境向けの設定を返します。
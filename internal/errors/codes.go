@@ -24,6 +24,7 @@ const (
 	ErrFileInvalidYAML ErrorCode = "FILE_INVALID_YAML"
 	ErrFileWriteFailed ErrorCode = "FILE_WRITE_FAILED"
 	ErrFileReadFailed  ErrorCode = "FILE_READ_FAILED"
+	ErrFileModified    ErrorCode = "FILE_MODIFIED"
 )
 
 // ポート関連エラー
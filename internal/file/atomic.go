@@ -0,0 +1,100 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// defaultFileMode は新規作成するファイルのパーミッションです。
+const defaultFileMode os.FileMode = 0644
+
+// AtomicWriterImpl は一時ファイルに書き込んでから置き換えることで、原子的にファイルを書き込む実装です。
+// 書き込み中に中断しても、既存のファイルが途中まで書かれた状態になることはありません。
+type AtomicWriterImpl struct {
+	logger logger.Logger
+}
+
+// NewAtomicWriterImpl は新しいAtomicWriterImplを作成します。
+func NewAtomicWriterImpl(logger logger.Logger) *AtomicWriterImpl {
+	return &AtomicWriterImpl{
+		logger: logger,
+	}
+}
+
+// WriteAtomic はファイルを原子的に書き込みます。既存のファイルがある場合はそのパーミッションを引き継ぎます。
+func (w *AtomicWriterImpl) WriteAtomic(ctx context.Context, path string, data []byte) error {
+	mode := defaultFileMode
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return w.WriteAtomicWithMode(ctx, path, data, mode)
+}
+
+// WriteAtomicWithMode は同じディレクトリの一時ファイルに書き込み、fsync してからリネームで置き換えます。
+func (w *AtomicWriterImpl) WriteAtomicWithMode(ctx context.Context, path string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return writeError(fmt.Sprintf("ディレクトリ作成に失敗: %s", dir), dir, err)
+	}
+
+	// リネームが原子的になるよう、一時ファイルは同じディレクトリ（同じファイルシステム）に作成する
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return writeError(fmt.Sprintf("一時ファイルの作成に失敗: %s", path), path, err)
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return writeError(fmt.Sprintf("ファイル書き込みに失敗: %s", tmpPath), path, err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return writeError(fmt.Sprintf("パーミッションの設定に失敗: %s", tmpPath), path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return writeError(fmt.Sprintf("ファイルの同期に失敗: %s", tmpPath), path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return writeError(fmt.Sprintf("ファイル書き込みに失敗: %s", tmpPath), path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return writeError(fmt.Sprintf("ファイルの置き換えに失敗: %s", path), path, err)
+	}
+	committed = true
+
+	// リネームをディスクに反映する（ディレクトリの fsync に対応しない環境では無視する）
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	w.logger.Debug(ctx, "ファイルを原子的に書き込みました",
+		types.Field{Key: "file_path", Value: path},
+		types.Field{Key: "size", Value: len(data)})
+
+	return nil
+}
+
+// writeError はファイル書き込みエラーを作成します。
+func writeError(message, path string, cause error) error {
+	return &errors.AppError{
+		Code:    errors.ErrFileWriteFailed,
+		Message: message,
+		Cause:   cause,
+		Fields: map[string]interface{}{
+			"file_path": path,
+		},
+	}
+}
@@ -0,0 +1,259 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+const (
+	// backupTimeFormat はバックアップファイル名に含める作成日時の形式です（名前順が作成順になります）。
+	backupTimeFormat = "20060102T150405.000000000Z"
+	// backupExt はバックアップファイルの拡張子です。
+	backupExt = ".bak"
+	// checksumExt はバックアップのチェックサム（sha256sum 形式）を記録するファイルの拡張子です。
+	checksumExt = ".sha256"
+)
+
+// BackupManagerImpl はファイルのバックアップを BackupDir に作成・管理する実装です。
+// バックアップは <元のファイル名>.<作成日時>.bak として保存し、内容の SHA-256 を .sha256 に記録します。
+type BackupManagerImpl struct {
+	dir    string
+	writer AtomicWriter
+	logger logger.Logger
+}
+
+// NewBackupManagerImpl は新しいBackupManagerImplを作成します。
+// dir が相対パスの場合は、カレントディレクトリを基準にします。
+func NewBackupManagerImpl(dir string, logger logger.Logger) *BackupManagerImpl {
+	return &BackupManagerImpl{
+		dir:    dir,
+		writer: NewAtomicWriterImpl(logger),
+		logger: logger,
+	}
+}
+
+// CreateBackup はファイルのバックアップを作成し、バックアップのパスを返します。
+func (m *BackupManagerImpl) CreateBackup(ctx context.Context, filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("ファイル読み込みに失敗しました: %s", filePath),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"file_path": filePath,
+			},
+		}
+	}
+
+	mode := defaultFileMode
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	createdAt := time.Now().UTC()
+	backupPath := filepath.Join(m.backupDir(filePath), filepath.Base(filePath)+"."+createdAt.Format(backupTimeFormat)+backupExt)
+	checksum := sha256Hex(content)
+
+	if err := m.writer.WriteAtomicWithMode(ctx, backupPath, content, mode); err != nil {
+		return "", err
+	}
+	if err := m.writer.WriteAtomicWithMode(ctx, backupPath+checksumExt, []byte(checksum+"  "+filepath.Base(backupPath)+"\n"), defaultFileMode); err != nil {
+		return "", err
+	}
+
+	m.logger.Debug(ctx, "バックアップを作成しました",
+		types.Field{Key: "file_path", Value: filePath},
+		types.Field{Key: "backup_path", Value: backupPath},
+		types.Field{Key: "checksum", Value: checksum})
+
+	return backupPath, nil
+}
+
+// RestoreBackup はバックアップの内容をチェックサムで検証してから、元のファイルに原子的に書き戻します。
+func (m *BackupManagerImpl) RestoreBackup(ctx context.Context, backupPath string, originalPath string) error {
+	content, err := os.ReadFile(backupPath)
+	if err != nil {
+		return &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("バックアップの読み込みに失敗しました: %s", backupPath),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"backup_path": backupPath,
+			},
+		}
+	}
+
+	if expected, err := readChecksum(backupPath); err == nil && expected != sha256Hex(content) {
+		return &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: fmt.Sprintf("バックアップのチェックサムが一致しません: %s", backupPath),
+			Fields: map[string]interface{}{
+				"backup_path": backupPath,
+			},
+		}
+	}
+
+	if err := m.writer.WriteAtomic(ctx, originalPath, content); err != nil {
+		return err
+	}
+
+	m.logger.Info(ctx, fmt.Sprintf("%s を %s から復元しました", originalPath, backupPath),
+		types.Field{Key: "file_path", Value: originalPath},
+		types.Field{Key: "backup_path", Value: backupPath})
+
+	return nil
+}
+
+// ListBackups はファイルのバックアップを新しい順に返します。
+func (m *BackupManagerImpl) ListBackups(ctx context.Context, originalPath string) ([]BackupInfo, error) {
+	dir := m.backupDir(originalPath)
+	prefix := filepath.Base(originalPath) + "."
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("バックアップディレクトリの読み込みに失敗しました: %s", dir),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"directory": dir,
+			},
+		}
+	}
+
+	var backups []BackupInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, backupExt) {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), backupExt))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		backupPath := filepath.Join(dir, name)
+		checksum, err := readChecksum(backupPath)
+		if err != nil {
+			m.logger.Debug(ctx, "バックアップのチェックサムを読み取れませんでした",
+				types.Field{Key: "backup_path", Value: backupPath},
+				types.Field{Key: "error", Value: err.Error()})
+		}
+
+		backups = append(backups, BackupInfo{
+			Path:         backupPath,
+			OriginalPath: originalPath,
+			CreatedAt:    createdAt,
+			Size:         info.Size(),
+			Checksum:     checksum,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// CleanupOldBackups は作成から maxAge を過ぎたバックアップを削除します。
+// 復元できるよう、最新のバックアップは経過時間に関わらず残します。
+func (m *BackupManagerImpl) CleanupOldBackups(ctx context.Context, originalPath string, maxAge time.Duration) error {
+	backups, err := m.ListBackups(ctx, originalPath)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for i, backup := range backups {
+		if i == 0 || backup.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := m.RemoveBackup(ctx, backup.Path); err != nil {
+			return err
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		m.logger.Debug(ctx, "古いバックアップを削除しました",
+			types.Field{Key: "file_path", Value: originalPath},
+			types.Field{Key: "removed", Value: removed})
+	}
+	return nil
+}
+
+// RemoveBackup はバックアップとそのチェックサムを削除します。
+func (m *BackupManagerImpl) RemoveBackup(ctx context.Context, backupPath string) error {
+	for _, path := range []string{backupPath, backupPath + checksumExt} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return &errors.AppError{
+				Code:    errors.ErrFileWriteFailed,
+				Message: fmt.Sprintf("バックアップの削除に失敗しました: %s", path),
+				Cause:   err,
+				Fields: map[string]interface{}{
+					"backup_path": path,
+				},
+			}
+		}
+	}
+	m.logger.Debug(ctx, "バックアップを削除しました",
+		types.Field{Key: "backup_path", Value: backupPath})
+	return nil
+}
+
+// backupDir はファイルのバックアップを保存するディレクトリを返します。
+// カレントディレクトリ配下のファイルは、同名のファイルと区別できるよう相対パスのディレクトリ構成を保ちます。
+func (m *BackupManagerImpl) backupDir(filePath string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return m.dir
+	}
+	dir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return m.dir
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return m.dir
+	}
+	return filepath.Join(m.dir, rel)
+}
+
+// readChecksum はバックアップに対応する .sha256 からチェックサムを読み取ります。
+func readChecksum(backupPath string) (string, error) {
+	content, err := os.ReadFile(backupPath + checksumExt)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("チェックサムが空です: %s", backupPath+checksumExt)
+	}
+	return fields[0], nil
+}
+
+// sha256Hex は内容の SHA-256 を16進数で返します。
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
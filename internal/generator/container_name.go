@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// resolveContainerNameConflicts は container_name の衝突にプロジェクト名付きの名前を割り当てます。
+func (u *UnifiedOverrideGeneratorImpl) resolveContainerNameConflicts(ctx context.Context, conflicts []types.ContainerNameConflictInfo) {
+	for i := range conflicts {
+		conflict := &conflicts[i]
+		if conflict.ProjectName == "" {
+			u.logger.Warn(ctx, "プロジェクト名が不明なためコンテナ名を変更できません",
+				types.Field{Key: "service", Value: conflict.Service},
+				types.Field{Key: "container_name", Value: conflict.ContainerName})
+			continue
+		}
+
+		resolvedName := fmt.Sprintf("%s-%s", conflict.ContainerName, conflict.ProjectName)
+		conflict.Resolution = &types.ContainerNameResolutionInfo{
+			ResolvedName: resolvedName,
+			Reason:       fmt.Sprintf("コンテナ名 %s から %s への自動変更", conflict.ContainerName, resolvedName),
+		}
+
+		u.logger.Info(ctx, "コンテナ名衝突解決",
+			types.Field{Key: "service", Value: conflict.Service},
+			types.Field{Key: "from", Value: conflict.ContainerName},
+			types.Field{Key: "to", Value: resolvedName})
+	}
+}
+
+// generateContainerNameOverrides は解決済みのコンテナ名をoverrideに書き込みます。
+// コンテナ名で参照している links / external_links / volumes_from / network_mode も新しい名前に書き換えます。
+func (u *UnifiedOverrideGeneratorImpl) generateContainerNameOverrides(ctx context.Context, config *types.ComposeConfig, conflicts []types.ContainerNameConflictInfo, override *types.OverrideConfig) {
+	renamed := make(map[string]string) // 旧コンテナ名 -> 新コンテナ名
+	for _, conflict := range conflicts {
+		if conflict.Resolution == nil {
+			continue
+		}
+		renamed[conflict.ContainerName] = conflict.Resolution.ResolvedName
+
+		serviceOverride := override.Services[conflict.Service]
+		serviceOverride.ContainerName = conflict.Resolution.ResolvedName
+		override.Services[conflict.Service] = serviceOverride
+	}
+	if len(renamed) == 0 {
+		return
+	}
+
+	for serviceName, service := range config.Services {
+		links, linksChanged := renameLinks(service.Links, renamed, config.Services)
+		externalLinks, externalChanged := renameLinks(service.ExternalLinks, renamed, nil)
+		volumesFrom, volumesChanged := renameContainerRefs(service.VolumesFrom, renamed)
+		networkMode, networkChanged := renameContainerRef(service.NetworkMode, renamed)
+		if !linksChanged && !externalChanged && !volumesChanged && !networkChanged {
+			continue
+		}
+
+		serviceOverride := override.Services[serviceName]
+		if linksChanged {
+			serviceOverride.Links = links
+		}
+		if externalChanged {
+			serviceOverride.ExternalLinks = externalLinks
+		}
+		if volumesChanged {
+			serviceOverride.VolumesFrom = volumesFrom
+		}
+		if networkChanged {
+			serviceOverride.NetworkMode = networkMode
+		}
+		override.Services[serviceName] = serviceOverride
+
+		u.logger.Debug(ctx, "コンテナ名の参照を書き換えました",
+			types.Field{Key: "service", Value: serviceName})
+	}
+}
+
+// renameLinks は "name" / "name:alias" 形式のリンクのうち、付け替えたコンテナ名を参照するものを書き換えます。
+// 元の名前をエイリアスとして残すため、エイリアスが無い場合は "新しい名前:元の名前" にします。
+// services が指定された場合、同名のサービスへのリンク（サービス名参照）は書き換えません。
+func renameLinks(links []string, renamed map[string]string, services map[string]types.Service) ([]string, bool) {
+	changed := false
+	result := make([]string, len(links))
+	for i, link := range links {
+		result[i] = link
+		name, alias, hasAlias := strings.Cut(link, ":")
+		newName, ok := renamed[name]
+		if !ok {
+			continue
+		}
+		if _, isService := services[name]; isService {
+			continue
+		}
+		if !hasAlias {
+			alias = name
+		}
+		result[i] = newName + ":" + alias
+		changed = true
+	}
+	return result, changed
+}
+
+// renameContainerRefs は "container:name[:mode]" 形式の参照を書き換えます。
+func renameContainerRefs(refs []string, renamed map[string]string) ([]string, bool) {
+	changed := false
+	result := make([]string, len(refs))
+	for i, ref := range refs {
+		newRef, ok := renameContainerRef(ref, renamed)
+		result[i] = newRef
+		changed = changed || ok
+	}
+	return result, changed
+}
+
+// renameContainerRef は "container:name[:mode]" 形式の単一の参照を書き換えます。
+func renameContainerRef(ref string, renamed map[string]string) (string, bool) {
+	rest, ok := strings.CutPrefix(ref, "container:")
+	if !ok {
+		return ref, false
+	}
+	name, mode, hasMode := strings.Cut(rest, ":")
+	newName, ok := renamed[name]
+	if !ok {
+		return ref, false
+	}
+	if hasMode {
+		return "container:" + newName + ":" + mode, true
+	}
+	return "container:" + newName, true
+}
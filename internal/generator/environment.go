@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// hostPortReference は環境変数の値に含まれる、ホストを指す host:port 形式の参照です。
+var hostPortReference = regexp.MustCompile(`(?i)(host\.docker\.internal|localhost|127\.0\.0\.1|\[::1\]):(\d{1,5})`)
+
+// RewriteEnvironment は付け替えたホストポートを参照している環境変数（env_file の値を含む）を
+// 新しいポートに書き換え、overrideの environment に追加して書き換えた内容を返します。
+// host.docker.internal:<ポート> は全サービス、localhost / 127.0.0.1 / [::1] はホストのネットワークを使う
+// network_mode: host のサービスのみを対象とします（それ以外ではコンテナ自身を指すため）。
+// 付け替え後も元のポートを公開しているサービスがある場合や、同じポートを複数のポートに付け替えた場合は、
+// どちらを指すか判断できないため書き換えません。
+func (u *UnifiedOverrideGeneratorImpl) RewriteEnvironment(ctx context.Context, config *types.ComposeConfig, conflictInfo *types.UnifiedConflictInfo, override *types.OverrideConfig) []types.EnvironmentRewrite {
+	remapped := remappedHostPorts(conflictInfo.PortConflicts)
+	if len(remapped) == 0 {
+		return nil
+	}
+	published := publishedHostPorts(config, override)
+
+	var rewrites []types.EnvironmentRewrite
+	for _, serviceName := range sortedKeys(config.Services) {
+		service := config.Services[serviceName]
+		values, sources := effectiveEnvironment(service)
+
+		for _, key := range sortedKeys(values) {
+			value := values[key]
+			rewritten, skipped := rewriteHostPorts(value, remapped, published, service.UsesHostNetwork())
+			for _, port := range skipped {
+				u.logger.Info(ctx, fmt.Sprintf("環境変数 %s の %s はポート %d を参照していますが、付け替え先が1つに決まらないか付け替え後も公開されているため書き換えません",
+					serviceName, key, port),
+					types.Field{Key: "service", Value: serviceName},
+					types.Field{Key: "variable", Value: key})
+			}
+			if rewritten == value {
+				continue
+			}
+
+			serviceOverride := override.Services[serviceName]
+			if serviceOverride.Environment == nil {
+				serviceOverride.Environment = make(map[string]string)
+			}
+			serviceOverride.Environment[key] = rewritten
+			override.Services[serviceName] = serviceOverride
+
+			rewrites = append(rewrites, types.EnvironmentRewrite{
+				Service:  serviceName,
+				Variable: key,
+				From:     value,
+				To:       rewritten,
+				Source:   sources[key],
+			})
+		}
+	}
+
+	return rewrites
+}
+
+// remappedHostPorts は付け替えたホストポートの対応（元のポート -> 新しいポート）を返します。
+// 範囲指定の場合は範囲内の各ポートを対応させます。
+// 同じポートを複数のサービスで別々のポートに付け替えた場合は、どちらを指すか判断できないため 0 にします。
+func remappedHostPorts(conflicts []types.PortConflictInfo) map[int]int {
+	remapped := make(map[int]int)
+	for _, conflict := range conflicts {
+		if conflict.Resolution == nil || conflict.Resolution.ResolvedPort == 0 {
+			continue
+		}
+		end := conflict.Port
+		if conflict.PortEnd > conflict.Port {
+			end = conflict.PortEnd
+		}
+		for port := conflict.Port; port <= end; port++ {
+			newPort := conflict.Resolution.ResolvedPort + (port - conflict.Port)
+			if existing, exists := remapped[port]; exists && existing != newPort {
+				newPort = 0
+			}
+			remapped[port] = newPort
+		}
+	}
+	return remapped
+}
+
+// publishedHostPorts は override 適用後に公開されるホストポートを返します。
+func publishedHostPorts(config *types.ComposeConfig, override *types.OverrideConfig) map[int]bool {
+	published := make(map[int]bool)
+	for serviceName, service := range config.Services {
+		ports := service.Ports
+		if serviceOverride, exists := override.Services[serviceName]; exists && len(serviceOverride.Ports) > 0 {
+			ports = serviceOverride.Ports
+		}
+		for _, mapping := range ports {
+			for _, port := range mapping.HostPorts() {
+				published[port] = true
+			}
+		}
+	}
+	return published
+}
+
+// effectiveEnvironment はサービスのコンテナに渡る環境変数と、その定義元を返します。
+// env_file は後に指定したものほど優先され、environment の値は env_file より優先されます。
+func effectiveEnvironment(service types.Service) (map[string]string, map[string]string) {
+	values := make(map[string]string)
+	sources := make(map[string]string)
+	for _, envFile := range service.EnvFiles {
+		for key, value := range envFile.Values {
+			values[key] = value
+			sources[key] = envFile.Path
+		}
+	}
+	for key, value := range service.Environment {
+		values[key] = value
+		sources[key] = "environment"
+	}
+	return values, sources
+}
+
+// rewriteHostPorts は値に含まれる host:port のうち、付け替えたポートを新しいポートに置き換えます。
+// 付け替え先が1つに決まらない、または付け替え後も公開されているため書き換えなかったポートを2つ目の戻り値で返します。
+func rewriteHostPorts(value string, remapped map[int]int, published map[int]bool, hostNetwork bool) (string, []int) {
+	var skipped []int
+	result := []byte{}
+	last := 0
+	for _, match := range hostPortReference.FindAllStringSubmatchIndex(value, -1) {
+		hostStart, portStart, portEnd := match[2], match[4], match[5]
+
+		// ホスト名・ポート番号の一部にマッチした場合（例: myhost.docker.internal, :80801）は対象外
+		if hostStart > 0 && isHostNameChar(value[hostStart-1]) {
+			continue
+		}
+		if portEnd < len(value) && value[portEnd] >= '0' && value[portEnd] <= '9' {
+			continue
+		}
+		if !hostNetwork && !hostDockerInternal(value[hostStart:match[3]]) {
+			continue
+		}
+
+		port, _ := strconv.Atoi(value[portStart:portEnd])
+		newPort, exists := remapped[port]
+		if !exists {
+			continue
+		}
+		if newPort == 0 || published[port] {
+			skipped = append(skipped, port)
+			continue
+		}
+
+		result = append(result, value[last:portStart]...)
+		result = append(result, strconv.Itoa(newPort)...)
+		last = portEnd
+	}
+	if last == 0 {
+		return value, skipped
+	}
+	return string(append(result, value[last:]...)), skipped
+}
+
+// isHostNameChar はホスト名に使える文字かどうかを返します。
+func isHostNameChar(c byte) bool {
+	return c == '.' || c == '-' || c == '_' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// hostDockerInternal は host.docker.internal かどうかを返します（大文字小文字は区別しません）。
+func hostDockerInternal(host string) bool {
+	return strings.EqualFold(host, "host.docker.internal")
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// variableReference はComposeファイル内の変数参照（${VAR} / $VAR）です。$$ はエスケープのため除きます。
+var variableReference = regexp.MustCompile(`(?:^|[^$])\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+
+// fingerprintEnvVars は参照の有無に関わらず生成結果に影響する環境変数です。
+var fingerprintEnvVars = []string{"COMPOSE_FILE", "COMPOSE_PATH_SEPARATOR", "COMPOSE_PROFILES", "COMPOSE_PROJECT_NAME"}
+
+// ComputeFingerprint は生成に使った入力から指紋（SHA-256）を求めます。
+// 対象は Compose ファイル（filePaths。include・extends で読み込んだファイルを含む）と env_file の内容、変数展開に使う envFiles（--env-file。空の場合は Compose ファイルと同じディレクトリの .env）、
+// Compose ファイルで参照している環境変数と COMPOSE_* の値、有効なプロファイル、extraFiles（テンプレートなど）の内容です。
+// 標準入力（-）を含む場合は内容を読み直せないため空文字列を返します。
+func ComputeFingerprint(config *types.ComposeConfig, filePaths []string, envFiles []string, profiles []string, extraFiles ...string) (string, error) {
+	var entries []string
+	variables := make(map[string]bool)
+	for _, name := range fingerprintEnvVars {
+		variables[name] = true
+	}
+
+	for _, filePath := range filePaths {
+		if filePath == "-" {
+			return "", nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, fmt.Sprintf("file %s %s", filePath, sha256Hex(content)))
+		for _, match := range variableReference.FindAllStringSubmatch(string(content), -1) {
+			variables[match[1]] = true
+		}
+	}
+
+	// .env と env_file は存在しない場合も区別できるよう "missing" として記録する
+	files := append([]string{}, envFiles...)
+	if len(envFiles) == 0 && len(filePaths) > 0 {
+		files = append(files, filepath.Join(filepath.Dir(filePaths[0]), ".env"))
+	}
+	if config != nil {
+		for _, serviceName := range sortedKeys(config.Services) {
+			for _, envFile := range config.Services[serviceName].EnvFiles {
+				files = append(files, envFile.Path)
+			}
+		}
+	}
+	for _, path := range files {
+		hash := "missing"
+		if content, err := os.ReadFile(path); err == nil {
+			hash = sha256Hex(content)
+		}
+		entries = append(entries, fmt.Sprintf("env_file %s %s", path, hash))
+	}
+
+	for _, name := range sortedKeys(variables) {
+		if value, ok := os.LookupEnv(name); ok {
+			entries = append(entries, fmt.Sprintf("env %s=%s", name, value))
+		} else {
+			entries = append(entries, fmt.Sprintf("env %s unset", name))
+		}
+	}
+
+	for _, path := range extraFiles {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, fmt.Sprintf("extra %s %s", path, sha256Hex(content)))
+	}
+
+	sortedProfiles := append([]string{}, profiles...)
+	sort.Strings(sortedProfiles)
+	entries = append(entries, "profiles "+strings.Join(sortedProfiles, ","))
+
+	return sha256Hex([]byte(strings.Join(entries, "\n"))), nil
+}
+
+// sha256Hex は内容の SHA-256 を16進数で返します。
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
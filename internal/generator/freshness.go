@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// OverrideState は既存のoverrideが現在の入力に対して有効かどうかを表します。
+type OverrideState string
+
+const (
+	// OverrideStateFresh は入力が変わっておらず、そのまま使えることを表します。
+	OverrideStateFresh OverrideState = "fresh"
+	// OverrideStateStale はComposeファイルや環境変数が変更され、再生成が必要なことを表します。
+	OverrideStateStale OverrideState = "stale"
+	// OverrideStateInvalid は存在しないサービスを参照している、割り当てたポートが使われているなど、
+	// そのまま使うと問題が起きることを表します。
+	OverrideStateInvalid OverrideState = "invalid"
+	// OverrideStateMissing はoverrideが存在しないことを表します。
+	OverrideStateMissing OverrideState = "missing"
+)
+
+// OverrideStatus は既存のoverrideの判定結果です。
+type OverrideStatus struct {
+	Path     string                  `json:"path" yaml:"path"`
+	State    OverrideState           `json:"state" yaml:"state"`
+	Reasons  []string                `json:"reasons,omitempty" yaml:"reasons,omitempty"`
+	Metadata *types.OverrideMetadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// NeedsRegeneration はoverrideを生成し直す必要があるかを返します。
+func (s *OverrideStatus) NeedsRegeneration() bool {
+	return s.State != OverrideStateFresh
+}
+
+// FreshnessCheck は既存のoverrideと比較する現在の状態です。
+type FreshnessCheck struct {
+	// Config は現在のComposeファイルの解析結果です。
+	Config *types.ComposeConfig
+	// Fingerprint は現在の入力の指紋です（ComputeFingerprint）。空の場合は比較しません。
+	Fingerprint string
+	// Conflicts は現在検知した衝突です。overrideで解決済みのもの以外があれば invalid とします。
+	Conflicts *types.UnifiedConflictInfo
+	// UsedPorts はシステムで使用中のホストポートです。
+	UsedPorts map[int]bool
+	// ProjectPorts はこのプロジェクトのコンテナが公開しているホストポートです（使用中でも問題としません）。
+	ProjectPorts map[int]bool
+	// Format は指定されたoverrideの出力形式です。既存のoverrideと異なる場合は stale とします（空の場合は比較しません）。
+	Format OverrideFormat
+	// ListSyntax はComposeのバージョンから決めたリストの置き換え方です。既存のoverrideと異なる場合は stale とします（空の場合は比較しません）。
+	ListSyntax ListSyntax
+}
+
+// CheckFreshness は既存のoverrideが現在の入力とポートの使用状況に対して最新かを判定します。
+// 元ファイル・環境変数の変更は stale、overrideをそのまま使うと問題が起きる場合は invalid になります。
+func (m *MetadataManagerImpl) CheckFreshness(ctx context.Context, path string, check FreshnessCheck) (*OverrideStatus, error) {
+	status := &OverrideStatus{Path: path}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		status.State = OverrideStateMissing
+		return status, nil
+	}
+
+	metadata, err := m.ReadMetadata(ctx, path)
+	if err != nil || metadata == nil {
+		status.State = OverrideStateInvalid
+		status.Reasons = append(status.Reasons, "gopose のメタデータを読み取れません")
+		return status, nil
+	}
+	status.Metadata = metadata
+
+	var invalid, stale []string
+
+	// overrideの内容が現在の設定・ポートの状況と矛盾していないか
+	for _, resolution := range metadata.Resolutions {
+		if _, exists := check.Config.Services[resolution.Service]; !exists {
+			invalid = append(invalid, fmt.Sprintf("サービス %s は現在のComposeファイルにありません", resolution.Service))
+			continue
+		}
+		if check.UsedPorts[resolution.ResolvedPort] && !check.ProjectPorts[resolution.ResolvedPort] {
+			invalid = append(invalid, fmt.Sprintf("割り当てたポート %d（%s）は他のプロセスが使用しています", resolution.ResolvedPort, resolution.Service))
+		}
+	}
+	for _, resolution := range metadata.NetworkResolutions {
+		if _, exists := check.Config.Networks[resolution.Network]; !exists {
+			invalid = append(invalid, fmt.Sprintf("ネットワーク %s は現在のComposeファイルにありません", resolution.Network))
+		}
+	}
+	if check.Conflicts != nil {
+		invalid = append(invalid, unresolvedConflicts(metadata, check)...)
+	}
+
+	// 生成後に手動で編集されていないか、出力形式が変わっていないか
+	if content, err := os.ReadFile(path); err == nil {
+		if unchanged, err := VerifyChecksum(content); err == nil && !unchanged {
+			stale = append(stale, "生成後に手動で編集されています")
+		}
+		if current := DetectOverrideFormat(content); check.Format != "" && current != check.Format {
+			stale = append(stale, fmt.Sprintf("出力形式が %s から %s に変更されました", current, check.Format))
+		}
+	}
+
+	// インストールされているComposeで使えるリストの置き換え方か
+	if previous := ListSyntax(metadata.ListSyntax); check.ListSyntax != "" {
+		if previous == "" {
+			previous = ListSyntaxOverride
+		}
+		if previous != check.ListSyntax {
+			stale = append(stale, fmt.Sprintf("リストの置き換え方が !%s から !%s に変わりました（Docker Composeのバージョンが変更されました）", previous, check.ListSyntax))
+		}
+	}
+
+	// テンプレートから生成したファイルが残っているか
+	for _, generated := range metadata.GeneratedFiles {
+		content, err := os.ReadFile(generated.Path)
+		switch {
+		case err != nil:
+			stale = append(stale, fmt.Sprintf("生成したファイル %s がありません", generated.Path))
+		case sha256Hex(content) != generated.SHA256:
+			stale = append(stale, fmt.Sprintf("生成したファイル %s が変更されています", generated.Path))
+		}
+	}
+
+	// 入力が変わっていないか
+	if metadata.Fingerprint == "" || check.Fingerprint == "" {
+		stale = append(stale, "入力の指紋が記録されていないため比較できません")
+	} else if metadata.Fingerprint != check.Fingerprint {
+		changed := changedSources(metadata.Sources)
+		if len(changed) == 0 {
+			changed = []string{"環境変数・.env・プロファイル・テンプレートのいずれかが変更されました"}
+		}
+		stale = append(stale, changed...)
+	}
+
+	switch {
+	case len(invalid) > 0:
+		status.State = OverrideStateInvalid
+	case len(stale) > 0:
+		status.State = OverrideStateStale
+	default:
+		status.State = OverrideStateFresh
+	}
+	status.Reasons = append(invalid, stale...)
+
+	m.logger.Debug(ctx, "overrideの状態を判定しました",
+		types.Field{Key: "path", Value: path},
+		types.Field{Key: "state", Value: status.State},
+		types.Field{Key: "reasons", Value: status.Reasons})
+
+	return status, nil
+}
+
+// unresolvedConflicts はoverrideで解決していない衝突を返します（このプロジェクト自身のコンテナが使うポートは除く）。
+func unresolvedConflicts(metadata *types.OverrideMetadata, check FreshnessCheck) []string {
+	resolved := make(map[string]bool)
+	for _, resolution := range metadata.Resolutions {
+		resolved[fmt.Sprintf("%s/%d", resolution.Service, resolution.OriginalPort)] = true
+	}
+	resolvedNetworks := make(map[string]bool)
+	for _, resolution := range metadata.NetworkResolutions {
+		resolvedNetworks[resolution.Network] = true
+	}
+
+	var reasons []string
+	for _, conflict := range check.Conflicts.PortConflicts {
+		if resolved[fmt.Sprintf("%s/%d", conflict.ServiceName, conflict.Port)] {
+			continue
+		}
+		if conflict.Type == types.ConflictTypeSystem && check.ProjectPorts[conflict.Port] {
+			continue
+		}
+		reasons = append(reasons, fmt.Sprintf("新しい衝突: %s のポート %d", conflict.ServiceName, conflict.Port))
+	}
+	for _, conflict := range check.Conflicts.NetworkConflicts {
+		if !resolvedNetworks[conflict.NetworkName] {
+			reasons = append(reasons, fmt.Sprintf("新しい衝突: ネットワーク %s", conflict.NetworkName))
+		}
+	}
+	return reasons
+}
+
+// changedSources は記録したハッシュと内容が異なるComposeファイルを返します。
+func changedSources(sources []types.SourceFile) []string {
+	var changed []string
+	for _, source := range sources {
+		if source.SHA256 == "" {
+			continue
+		}
+		hash, err := fileSHA256(source.Path)
+		switch {
+		case err != nil:
+			changed = append(changed, fmt.Sprintf("%s を読み込めません", source.Path))
+		case hash != source.SHA256:
+			changed = append(changed, fmt.Sprintf("%s が変更されました", source.Path))
+		}
+	}
+	return changed
+}
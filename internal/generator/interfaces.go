@@ -54,6 +54,8 @@ type GenerationData struct {
 	Services       []types.Service            `json:"services"`
 	Metadata       *types.OverrideMetadata    `json:"metadata"`
 	Options        GenerationOptions          `json:"options"`
+	// Override は gopose が生成したoverride（テンプレートのヘルパー関数が解決後の値を参照します）です。
+	Override *types.OverrideConfig `json:"override"`
 }
 
 // GenerationOptions は生成オプションを表します。
@@ -0,0 +1,248 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// OverrideFormat はoverrideファイルの出力形式です（OutputFormat の別名）。
+type OverrideFormat = OutputFormat
+
+const (
+	OverrideFormatYAML = OutputFormatYAML
+	OverrideFormatJSON = OutputFormatJSON
+)
+
+// OverrideFormatFromPath は出力先の拡張子から形式を決めます（.json 以外はYAML）。
+func OverrideFormatFromPath(path string) OverrideFormat {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return OverrideFormatJSON
+	}
+	return OverrideFormatYAML
+}
+
+// DetectOverrideFormat は生成済みのoverrideの内容から形式を判定します。
+func DetectOverrideFormat(content []byte) OverrideFormat {
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		return OverrideFormatJSON
+	}
+	return OverrideFormatYAML
+}
+
+// generateOverrideJSON はoverrideをJSON形式で生成します。
+// JSONにはコメントを書けないため、gopose が生成したことは x-gopose-metadata で識別します。
+// JSONには !override を書けないため、リストはタグを付けずに出力し、元のリストは !reset のファイル（YAML）で取り除きます。
+func (g *OverrideGeneratorImpl) generateOverrideJSON(override *types.OverrideConfig) (string, error) {
+	// YAMLと同じキー・値で出力するため、メタデータはYAMLのノードを経由して変換する
+	node, err := metadataNode(override.Metadata)
+	if err != nil {
+		return "", err
+	}
+	var metadata map[string]interface{}
+	if err := node.Decode(&metadata); err != nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrFileWriteFailed,
+			Message: "メタデータの生成に失敗しました",
+			Cause:   err,
+		}
+	}
+
+	root := map[string]interface{}{
+		metadataKey: metadata,
+	}
+	if override.Name != "" {
+		root["name"] = override.Name
+	}
+
+	services := make(map[string]interface{})
+	for serviceName, serviceOverride := range override.Services {
+		service := make(map[string]interface{})
+
+		if serviceOverride.ContainerName != "" {
+			service["container_name"] = serviceOverride.ContainerName
+		}
+		if serviceOverride.NetworkMode != "" {
+			service["network_mode"] = serviceOverride.NetworkMode
+		}
+		setStringList(service, "links", serviceOverride.Links)
+		setStringList(service, "external_links", serviceOverride.ExternalLinks)
+		setStringList(service, "volumes_from", serviceOverride.VolumesFrom)
+
+		if len(serviceOverride.Environment) > 0 {
+			environment := make(map[string]interface{})
+			for key, value := range serviceOverride.Environment {
+				environment[key] = escapeInterpolation(value)
+			}
+			service["environment"] = environment
+		}
+
+		if len(serviceOverride.Ports) > 0 {
+			ports := make([]interface{}, 0, len(serviceOverride.Ports))
+			for _, port := range serviceOverride.Ports {
+				if port.LongSyntax {
+					ports = append(ports, longSyntaxPortFields(port))
+				} else {
+					ports = append(ports, port.ShortSyntax())
+				}
+			}
+			service["ports"] = ports
+		}
+
+		if len(serviceOverride.Networks) > 0 {
+			networks := make(map[string]interface{})
+			for netName, netConfig := range serviceOverride.Networks {
+				if netConfig.IPv4Address != "" {
+					networks[netName] = map[string]interface{}{"ipv4_address": netConfig.IPv4Address}
+				} else {
+					networks[netName] = nil
+				}
+			}
+			service["networks"] = networks
+		}
+
+		services[serviceName] = service
+	}
+	root["services"] = services
+
+	if len(override.Networks) > 0 {
+		networks := make(map[string]interface{})
+		for netName, netOverride := range override.Networks {
+			network := make(map[string]interface{})
+			if len(netOverride.IPAM.Config) > 0 {
+				var configs []interface{}
+				for _, cfg := range netOverride.IPAM.Config {
+					configs = append(configs, map[string]interface{}{"subnet": cfg.Subnet})
+				}
+				network["ipam"] = map[string]interface{}{"config": configs}
+			}
+			networks[netName] = network
+		}
+		root["networks"] = networks
+	}
+
+	if len(override.Volumes) > 0 {
+		volumes := make(map[string]interface{})
+		for volumeName, volumeOverride := range override.Volumes {
+			volumes[volumeName] = map[string]interface{}{"name": volumeOverride.Name}
+		}
+		root["volumes"] = volumes
+	}
+
+	// テンプレートの設定を追加する（gopose が生成した値を優先）
+	if len(override.TemplateContent) > 0 {
+		extra, err := templateRootNode(override.TemplateContent)
+		if err != nil {
+			return "", err
+		}
+		if extra != nil {
+			value, err := nodeJSONValue(extra)
+			if err != nil {
+				return "", err
+			}
+			mergeJSONValue(root, value.(map[string]interface{}))
+		}
+	}
+
+	var builder strings.Builder
+	writeJSONValue(&builder, root, "")
+	builder.WriteString("\n")
+	return builder.String(), nil
+}
+
+// setStringList は空でない文字列リストを設定します。
+func setStringList(service map[string]interface{}, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	service[key] = list
+}
+
+// longSyntaxPortFields は長形式のポートエントリを返します。
+func longSyntaxPortFields(port types.PortMapping) map[string]interface{} {
+	fields := map[string]interface{}{"target": port.Container}
+	if port.Host != 0 {
+		fields["published"] = port.Published()
+	}
+	if port.HostIP != "" {
+		fields["host_ip"] = port.HostIP
+	}
+	if port.Protocol != "" {
+		fields["protocol"] = port.Protocol
+	}
+	if port.Mode != "" {
+		fields["mode"] = port.Mode
+	}
+	if port.Name != "" {
+		fields["name"] = port.Name
+	}
+	if port.AppProtocol != "" {
+		fields["app_protocol"] = port.AppProtocol
+	}
+	return fields
+}
+
+// writeJSONValue はキーを名前順（x-gopose-metadata は最後）に並べ、2スペースでインデントしたJSONを書き出します。
+func writeJSONValue(builder *strings.Builder, value interface{}, indent string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			builder.WriteString("{}")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		// メタデータは本文のチェックサムの範囲外とするため、常に最後に出力する
+		sort.Slice(keys, func(i, j int) bool {
+			if (keys[i] == metadataKey) != (keys[j] == metadataKey) {
+				return keys[j] == metadataKey
+			}
+			return keys[i] < keys[j]
+		})
+
+		builder.WriteString("{\n")
+		for i, key := range keys {
+			builder.WriteString(indent + "  ")
+			writeJSONValue(builder, key, "")
+			builder.WriteString(": ")
+			writeJSONValue(builder, v[key], indent+"  ")
+			if i < len(keys)-1 {
+				builder.WriteString(",")
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString(indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			builder.WriteString("[]")
+			return
+		}
+		builder.WriteString("[\n")
+		for i, item := range v {
+			builder.WriteString(indent + "  ")
+			writeJSONValue(builder, item, indent+"  ")
+			if i < len(v)-1 {
+				builder.WriteString(",")
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString(indent + "]")
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			encoded = []byte("null")
+		}
+		builder.Write(encoded)
+	}
+}
@@ -0,0 +1,263 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// MetadataManagerImpl はoverrideに埋め込むメタデータ（x-gopose-metadata）の作成と読み取りを行う実装です。
+type MetadataManagerImpl struct {
+	version string
+	logger  logger.Logger
+}
+
+// NewMetadataManagerImpl は新しいMetadataManagerImplを作成します。version は gopose のバージョンです。
+func NewMetadataManagerImpl(version string, logger logger.Logger) *MetadataManagerImpl {
+	return &MetadataManagerImpl{
+		version: version,
+		logger:  logger,
+	}
+}
+
+// CreateMetadata はポートの解決内容からメタデータを作成します。
+func (m *MetadataManagerImpl) CreateMetadata(ctx context.Context, resolutions []types.ConflictResolution) (*types.OverrideMetadata, error) {
+	metadata := &types.OverrideMetadata{
+		Resolutions: resolutions,
+	}
+	m.stamp(metadata)
+	if err := m.ValidateMetadata(ctx, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// Populate は生成したoverrideのメタデータに、バージョン・生成日時・プロジェクト名・元ファイルのハッシュを記録します。
+// 解決内容はoverride生成時に記録済みのものをそのまま使います。
+// filePaths には include・extends で読み込んだファイルを含む解決済みの入力（ComposeConfig.FilePaths）を渡します。
+func (m *MetadataManagerImpl) Populate(ctx context.Context, metadata *types.OverrideMetadata, projectName string, filePaths []string) error {
+	m.stamp(metadata)
+	metadata.Project = projectName
+
+	metadata.Sources = make([]types.SourceFile, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		source := types.SourceFile{Path: filePath}
+		// 標準入力は読み込み済みのため内容のハッシュは記録しない
+		if filePath != "-" {
+			hash, err := fileSHA256(filePath)
+			if err != nil {
+				return &errors.AppError{
+					Code:    errors.ErrFileReadFailed,
+					Message: fmt.Sprintf("ファイルのハッシュを計算できませんでした: %s", filePath),
+					Cause:   err,
+					Fields: map[string]interface{}{
+						"file_path": filePath,
+					},
+				}
+			}
+			source.SHA256 = hash
+		}
+		metadata.Sources = append(metadata.Sources, source)
+	}
+
+	m.logger.Debug(ctx, "メタデータを記録しました",
+		types.Field{Key: "version", Value: metadata.Version},
+		types.Field{Key: "sources", Value: len(metadata.Sources)})
+
+	return m.ValidateMetadata(ctx, metadata)
+}
+
+// stamp は生成元・バージョン・生成日時を設定します（生成日時は秒単位のUTC）。
+func (m *MetadataManagerImpl) stamp(metadata *types.OverrideMetadata) {
+	metadata.GeneratedBy = generatedByMarker
+	metadata.Version = m.version
+	if metadata.GeneratedAt.IsZero() {
+		metadata.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+	}
+}
+
+// ValidateMetadata はメタデータが gopose の生成したものとして妥当かを検証します。
+func (m *MetadataManagerImpl) ValidateMetadata(ctx context.Context, metadata *types.OverrideMetadata) error {
+	if metadata == nil || metadata.GeneratedBy != generatedByMarker {
+		return &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: "gopose のメタデータではありません",
+		}
+	}
+	if metadata.Version == "" {
+		return &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: "メタデータに gopose のバージョンがありません",
+		}
+	}
+	if metadata.GeneratedAt.IsZero() {
+		return &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: "メタデータに生成日時がありません",
+		}
+	}
+
+	for _, resolution := range metadata.Resolutions {
+		if resolution.Service == "" || resolution.ResolvedPort < 1 || resolution.ResolvedPort > 65535 {
+			return &errors.AppError{
+				Code:    errors.ErrValidationFailed,
+				Message: fmt.Sprintf("メタデータのポート解決情報が無効です: %s %d→%d", resolution.Service, resolution.OriginalPort, resolution.ResolvedPort),
+				Fields: map[string]interface{}{
+					"service": resolution.Service,
+				},
+			}
+		}
+	}
+	for _, resolution := range metadata.NetworkResolutions {
+		if resolution.Network == "" || resolution.ResolvedSubnet == "" {
+			return &errors.AppError{
+				Code:    errors.ErrValidationFailed,
+				Message: fmt.Sprintf("メタデータのネットワーク解決情報が無効です: %s", resolution.Network),
+				Fields: map[string]interface{}{
+					"network": resolution.Network,
+				},
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExtractMetadata はoverride設定からメタデータを取り出します。
+func (m *MetadataManagerImpl) ExtractMetadata(ctx context.Context, override *types.OverrideConfig) (*types.OverrideMetadata, error) {
+	metadata := override.Metadata
+	normalizeMetadata(&metadata)
+	if err := m.ValidateMetadata(ctx, &metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// ReadMetadata はoverrideファイル（YAML / JSON）の x-gopose-metadata を読み取ります。
+// メタデータを含まないファイル（手書きのoverrideなど）の場合は nil を返します。
+func (m *MetadataManagerImpl) ReadMetadata(ctx context.Context, path string) (*types.OverrideMetadata, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("ファイル読み込みに失敗しました: %s", path),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"file_path": path,
+			},
+		}
+	}
+
+	metadata, err := ExtractMetadataFromBytes(content)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		return nil, nil
+	}
+	if err := m.ValidateMetadata(ctx, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// ExtractMetadataFromBytes はoverrideファイルの内容から x-gopose-metadata を取り出します。
+// JSON形式のoverrideもYAMLとして読み込みます。メタデータが無い場合は nil を返します。
+func ExtractMetadataFromBytes(content []byte) (*types.OverrideMetadata, error) {
+	var document struct {
+		Metadata *types.OverrideMetadata `yaml:"x-gopose-metadata"`
+	}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "overrideファイルの解析に失敗しました",
+			Cause:   err,
+		}
+	}
+	if document.Metadata == nil {
+		return nil, nil
+	}
+	normalizeMetadata(document.Metadata)
+	return document.Metadata, nil
+}
+
+// normalizeMetadata は出力時に省略したエイリアスのフィールドを補います。
+func normalizeMetadata(metadata *types.OverrideMetadata) {
+	for i := range metadata.Resolutions {
+		resolution := &metadata.Resolutions[i]
+		if resolution.ServiceName == "" {
+			resolution.ServiceName = resolution.Service
+		}
+		if resolution.ConflictPort == 0 {
+			resolution.ConflictPort = resolution.OriginalPort
+		}
+		if resolution.Timestamp.IsZero() {
+			resolution.Timestamp = metadata.GeneratedAt
+		}
+	}
+}
+
+// metadataNode はメタデータを x-gopose-metadata に書き込むノードにします。
+func metadataNode(metadata types.OverrideMetadata) (*yaml.Node, error) {
+	if metadata.GeneratedBy == "" {
+		metadata.GeneratedBy = generatedByMarker
+	}
+	if metadata.Resolutions == nil {
+		metadata.Resolutions = []types.ConflictResolution{}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(metadata); err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileWriteFailed,
+			Message: "メタデータの生成に失敗しました",
+			Cause:   err,
+		}
+	}
+	return &node, nil
+}
+
+// overrideBody はoverrideファイルのうちメタデータより前の部分（本文）を返します。
+// メタデータは YAML・JSON のどちらでも最後のトップレベルキーとして出力するため、その行の直前までを本文とします。
+// メタデータの行が無い場合は false を返します。
+func overrideBody(content []byte) ([]byte, bool) {
+	for _, prefix := range []string{"\n" + metadataKey + ":", "\n  \"" + metadataKey + "\":"} {
+		if index := bytes.LastIndex(content, []byte(prefix)); index >= 0 {
+			return content[:index+1], true
+		}
+	}
+	return nil, false
+}
+
+// VerifyChecksum はoverrideファイルの本文が生成時から変更されていないかを返します。
+// チェックサムを記録していないファイル（古いバージョンで生成したものなど）は変更なしとみなします。
+func VerifyChecksum(content []byte) (bool, error) {
+	metadata, err := ExtractMetadataFromBytes(content)
+	if err != nil {
+		return false, err
+	}
+	if metadata == nil || metadata.Checksum == "" {
+		return true, nil
+	}
+	body, found := overrideBody(content)
+	if !found {
+		return false, nil
+	}
+	return sha256Hex(body) == metadata.Checksum, nil
+}
+
+// fileSHA256 はファイルの内容の SHA-256 を16進数で返します。
+func fileSHA256(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(content), nil
+}
@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// overrideTag はマージ時に元の値を追記せず置き換えることを指示するComposeのタグです。
+	overrideTag = "!override"
+	// resetTag はマージ時に元の値を取り除くことを指示するComposeのタグです（!override より前のバージョンから使えます）。
+	resetTag = "!reset"
+)
+
+// mappingNode は空のマッピングノードを作成します。
+func mappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+// sequenceNode は空のシーケンスノードを作成します（tag が空の場合は通常のリスト）。
+func sequenceNode(tag string) *yaml.Node {
+	if tag == "" {
+		tag = "!!seq"
+	}
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: tag}
+}
+
+// stringNode は文字列ノードを作成します（必要な場合のみクォートされます）。
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// quotedNode はダブルクォートで囲んだ文字列ノードを作成します。
+func quotedNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle}
+}
+
+// intNode は整数ノードを作成します。
+func intNode(value int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)}
+}
+
+// appendField はマッピングノードにキーと値を追加します。
+func appendField(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, stringNode(key), value)
+}
+
+// sortedKeys はマップのキーを名前順に返します。
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// templateRootNode は描画したテンプレートを解析し、トップレベルのマッピングを返します（空の場合は nil）。
+func templateRootNode(content []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "テンプレートの描画結果をYAMLとして解析できません",
+			Cause:   err,
+		}
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	// 重複したキーはComposeでもエラーになるため、マージする前に検出する
+	var check interface{}
+	if err := document.Decode(&check); err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: "テンプレートの描画結果が無効です",
+			Cause:   err,
+		}
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: "テンプレートの描画結果はトップレベルがマッピングである必要があります",
+		}
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == metadataKey {
+			return nil, &errors.AppError{
+				Code:    errors.ErrValidationFailed,
+				Message: fmt.Sprintf("テンプレートで %s は指定できません", metadataKey),
+			}
+		}
+	}
+	return root, nil
+}
+
+// mergeNode は extra のキーを base に追加します。
+// 両方がマッピングのキーは再帰的にマージし、それ以外は base（gopose が生成した値）を優先します。
+func mergeNode(base, extra *yaml.Node) {
+	for i := 0; i+1 < len(extra.Content); i += 2 {
+		key, value := extra.Content[i], extra.Content[i+1]
+		existing := mappingValue(base, key.Value)
+		switch {
+		case existing == nil:
+			base.Content = append(base.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeNode(existing, value)
+		}
+	}
+}
+
+// mappingValue はマッピングノードからキーに対応する値を返します（無い場合は nil）。
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// nodeJSONValue はノードをJSONの出力に使う値に変換します。
+// JSONにはタグが無いため、!override・!reset の付いた値はエラーにします。
+func nodeJSONValue(node *yaml.Node) (interface{}, error) {
+	if node.Tag == overrideTag || node.Tag == resetTag {
+		return nil, &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: fmt.Sprintf("テンプレートの %s はJSON形式では出力できません。--format yaml を指定してください", node.Tag),
+		}
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := nodeJSONValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			mapping[node.Content[i].Value] = value
+		}
+		return mapping, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := nodeJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case yaml.AliasNode:
+		return nodeJSONValue(node.Alias)
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: "テンプレートの値を変換できません",
+				Cause:   err,
+			}
+		}
+		return value, nil
+	}
+}
+
+// mergeJSONValue は mergeNode と同じ規則で extra のキーを base に追加します。
+func mergeJSONValue(base, extra map[string]interface{}) {
+	for key, value := range extra {
+		existing, exists := base[key]
+		if !exists {
+			base[key] = value
+			continue
+		}
+		existingMap, ok1 := existing.(map[string]interface{})
+		valueMap, ok2 := value.(map[string]interface{})
+		if ok1 && ok2 {
+			mergeJSONValue(existingMap, valueMap)
+		}
+	}
+}
@@ -3,17 +3,26 @@ package generator
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/file"
 	"github.com/harakeishi/gopose/internal/logger"
 	"github.com/harakeishi/gopose/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	// generatedByMarker はgoposeが生成したファイルのヘッダーに含まれる識別文字列です。
+	generatedByMarker = "Generated by gopose"
+	// metadataKey はoverrideファイルに埋め込むメタデータ拡張のキーです。
+	metadataKey = "x-gopose-metadata"
+)
+
 // OverrideGeneratorImpl はOverride生成の実装です。
 type OverrideGeneratorImpl struct {
 	logger logger.Logger
@@ -72,49 +81,246 @@ func (g *OverrideGeneratorImpl) GenerateOverride(ctx context.Context, config *ty
 	return override, nil
 }
 
-// WriteOverrideFile はoverride.ymlファイルをディスクに書き込みます。
-func (g *OverrideGeneratorImpl) WriteOverrideFile(ctx context.Context, override *types.OverrideConfig, outputPath string) error {
+// WriteOptions はoverrideファイルの書き込みオプションです。
+type WriteOptions struct {
+	// Force は生成後に手動で編集されたoverrideでも上書きします。
+	Force bool
+	// Backup は既存のoverrideを上書きする前にバックアップを作成します（nil の場合は作成しません）。
+	Backup file.BackupManager
+	// BackupRetention を過ぎたバックアップは書き込み後に削除します（0 の場合は削除しません）。
+	BackupRetention time.Duration
+	// Format はoverrideの出力形式です（空の場合は出力先の拡張子から決めます）。
+	Format OverrideFormat
+	// ComposeCommand はComposeが自動で読み込まない出力先の場合に、ヘッダーで案内する実行コマンドです。
+	ComposeCommand string
+}
+
+// WriteOverrideFile はoverride.ymlファイルをディスクに原子的に書き込みます。
+// 既存のoverrideが生成後に手動で編集されている場合は、opts.Force が指定されない限り上書きしません。
+func (g *OverrideGeneratorImpl) WriteOverrideFile(ctx context.Context, override *types.OverrideConfig, outputPath string, opts WriteOptions) error {
 	g.logger.Debug(ctx, "Overrideファイル書き込み開始",
 		types.Field{Key: "output_path", Value: outputPath})
 
-	// ディレクトリが存在しない場合は作成
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return &errors.AppError{
-			Code:    errors.ErrFileWriteFailed,
-			Message: fmt.Sprintf("ディレクトリ作成に失敗: %s", dir),
+	format := opts.Format
+	if format == "" {
+		format = OverrideFormatFromPath(outputPath)
+	}
+	finalContent, err := g.renderOverride(ctx, override, format, opts.ComposeCommand)
+	if err != nil {
+		return err
+	}
+
+	exists, edited, err := g.checkManualEdit(ctx, outputPath)
+	if err != nil {
+		return err
+	}
+	if edited && !opts.Force {
+		return manualEditError(outputPath)
+	}
+	backupPath, err := backupBeforeWrite(ctx, g.logger, outputPath, exists, edited, opts)
+	if err != nil {
+		return err
+	}
+
+	// 書き込み中に中断しても壊れたoverrideが残らないよう、一時ファイルからリネームで置き換える
+	if err := file.NewAtomicWriterImpl(g.logger).WriteAtomic(ctx, outputPath, finalContent); err != nil {
+		return err
+	}
+
+	cleanupBackups(ctx, g.logger, outputPath, opts)
+
+	g.logger.Info(ctx, "Overrideファイル書き込み完了",
+		types.Field{Key: "output_path", Value: outputPath},
+		types.Field{Key: "format", Value: format},
+		types.Field{Key: "backup_path", Value: backupPath},
+		types.Field{Key: "file_size", Value: len(finalContent)})
+
+	return nil
+}
+
+// manualEditError は生成後に手動で編集されたファイルの上書きを拒否するエラーを返します。
+func manualEditError(path string) error {
+	return &errors.AppError{
+		Code:    errors.ErrFileModified,
+		Message: fmt.Sprintf("%s は生成後に手動で編集されているため上書きしません。--force で上書きできます（編集内容はバックアップされます）", path),
+		Fields: map[string]interface{}{
+			"file_path": path,
+		},
+	}
+}
+
+// backupBeforeWrite は既存のファイルを上書きする前にバックアップし、バックアップのパスを返します。
+// バックアップが無効でも、手動で編集された内容は <path>.bak に残します。
+func backupBeforeWrite(ctx context.Context, log logger.Logger, path string, exists, edited bool, opts WriteOptions) (string, error) {
+	backupPath := ""
+	if exists && opts.Backup != nil {
+		var err error
+		if backupPath, err = opts.Backup.CreateBackup(ctx, path); err != nil {
+			return "", err
+		}
+	} else if edited {
+		backupPath = path + ".bak"
+		content, err := os.ReadFile(path)
+		if err == nil {
+			err = os.WriteFile(backupPath, content, 0644)
+		}
+		if err != nil {
+			return "", &errors.AppError{
+				Code:    errors.ErrFileWriteFailed,
+				Message: fmt.Sprintf("ファイル書き込みに失敗: %s", backupPath),
+				Cause:   err,
+				Fields: map[string]interface{}{
+					"file_path": backupPath,
+				},
+			}
+		}
+	}
+	if edited {
+		log.Warn(ctx, fmt.Sprintf("%s は生成後に手動で編集されていました。編集内容を %s に退避して上書きします", path, backupPath),
+			types.Field{Key: "file_path", Value: path},
+			types.Field{Key: "backup_path", Value: backupPath})
+	}
+	return backupPath, nil
+}
+
+// cleanupBackups は保持期間を過ぎたバックアップを削除します（失敗しても警告のみ）。
+func cleanupBackups(ctx context.Context, log logger.Logger, path string, opts WriteOptions) {
+	if opts.Backup == nil || opts.BackupRetention <= 0 {
+		return
+	}
+	if err := opts.Backup.CleanupOldBackups(ctx, path, opts.BackupRetention); err != nil {
+		log.Warn(ctx, "古いバックアップの削除に失敗しました",
+			types.Field{Key: "file_path", Value: path},
+			types.Field{Key: "error", Value: err.Error()})
+	}
+}
+
+// checkManualEdit は既存のoverrideの有無と、生成後に手動で編集されているかを返します。
+func (g *OverrideGeneratorImpl) checkManualEdit(ctx context.Context, path string) (bool, bool, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("ファイル読み込みに失敗しました: %s", path),
 			Cause:   err,
 			Fields: map[string]interface{}{
-				"directory": dir,
+				"file_path": path,
 			},
 		}
 	}
 
-	// ヘッダーコメントを追加
-	header := g.generateFileHeader()
+	unchanged, err := VerifyChecksum(content)
+	if err != nil {
+		// メタデータを解析できないほど編集されている場合も手動編集とみなす
+		g.logger.Debug(ctx, "overrideのチェックサムを検証できませんでした",
+			types.Field{Key: "file_path", Value: path},
+			types.Field{Key: "error", Value: err.Error()})
+		unchanged = false
+	}
+	return true, !unchanged, nil
+}
+
+// WriteOverride はoverrideの内容を指定した形式でWriterに書き込みます（-o - で標準出力に出力する場合など）。
+func (g *OverrideGeneratorImpl) WriteOverride(ctx context.Context, override *types.OverrideConfig, w io.Writer, format OverrideFormat) error {
+	content, err := g.renderOverride(ctx, override, format, "")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(content); err != nil {
+		return &errors.AppError{
+			Code:    errors.ErrFileWriteFailed,
+			Message: "overrideの出力に失敗しました",
+			Cause:   err,
+		}
+	}
 
-	// カスタムYAML生成（!overrideタグ付き）
-	yamlContent := g.generateOverrideYAML(override)
+	g.logger.Debug(ctx, "Overrideを出力しました",
+		types.Field{Key: "size", Value: len(content)})
 
-	finalContent := []byte(header + yamlContent)
+	return nil
+}
 
-	// ファイルに書き込み
-	if err := os.WriteFile(outputPath, finalContent, 0644); err != nil {
-		return &errors.AppError{
+// renderOverride はoverrideファイルの内容を生成します（YAMLの場合はヘッダーコメント付き）。
+// メタデータには本文のチェックサムを記録します。出力したポートが元のエントリと一致しない場合はエラーを返します。
+func (g *OverrideGeneratorImpl) renderOverride(ctx context.Context, override *types.OverrideConfig, format OverrideFormat, command string) ([]byte, error) {
+	// 本文はメタデータの内容に依存しないため、一度生成して求めたチェックサムを記録してから生成し直す
+	withChecksum := *override
+	withChecksum.Metadata.Checksum = ""
+	content, err := g.renderContent(&withChecksum, format, command)
+	if err != nil {
+		return nil, err
+	}
+	body, found := overrideBody(content)
+	if !found {
+		return nil, &errors.AppError{
 			Code:    errors.ErrFileWriteFailed,
-			Message: fmt.Sprintf("ファイル書き込みに失敗: %s", outputPath),
+			Message: "overrideのメタデータの位置を特定できませんでした",
+		}
+	}
+	withChecksum.Metadata.Checksum = sha256Hex(body)
+	if content, err = g.renderContent(&withChecksum, format, command); err != nil {
+		return nil, err
+	}
+
+	if err := g.verifyPortRoundTrip(ctx, override, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// renderContent は指定した形式でoverrideファイルの内容を生成します。
+func (g *OverrideGeneratorImpl) renderContent(override *types.OverrideConfig, format OverrideFormat, command string) ([]byte, error) {
+	var content []byte
+	if format == OverrideFormatJSON {
+		jsonContent, err := g.generateOverrideJSON(override)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(jsonContent)
+	} else {
+		// ヘッダーコメントを追加
+		header := g.generateFileHeader(command)
+
+		// カスタムYAML生成（!overrideタグ付き）
+		yamlContent, err := g.generateOverrideYAML(override)
+		if err != nil {
+			return nil, err
+		}
+
+		content = []byte(header + yamlContent)
+	}
+	return content, nil
+}
+
+// IsGeneratedByGopose は指定されたoverrideファイルがgoposeによって生成されたものかを判定します。
+// goposeのヘッダーコメントまたはメタデータ拡張を含まないファイルは手書きのoverrideとみなします。
+func (g *OverrideGeneratorImpl) IsGeneratedByGopose(ctx context.Context, path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("ファイル読み込みに失敗しました: %s", path),
 			Cause:   err,
 			Fields: map[string]interface{}{
-				"file_path": outputPath,
+				"file_path": path,
 			},
 		}
 	}
 
-	g.logger.Info(ctx, "Overrideファイル書き込み完了",
-		types.Field{Key: "output_path", Value: outputPath},
-		types.Field{Key: "file_size", Value: len(finalContent)})
+	// メタデータを読み取れない古い形式のファイルはヘッダーの識別文字列で判定する
+	generated := strings.Contains(string(content), generatedByMarker)
+	if metadata, err := ExtractMetadataFromBytes(content); err == nil && metadata != nil {
+		generated = metadata.GeneratedBy == generatedByMarker
+	}
 
-	return nil
+	g.logger.Debug(ctx, "overrideファイルの生成元を判定",
+		types.Field{Key: "file", Value: path},
+		types.Field{Key: "generated_by_gopose", Value: generated})
+
+	return generated, nil
 }
 
 // ValidateOverride はoverride設定の妥当性を検証します。
@@ -170,18 +376,19 @@ func (g *OverrideGeneratorImpl) generateServiceOverride(ctx context.Context, ser
 	portMappings := make([]types.PortMapping, len(originalService.Ports))
 	copy(portMappings, originalService.Ports)
 
+	used := make([]bool, len(portMappings))
 	for _, resolution := range resolutions {
-		// 対応するポートマッピングを検索して更新
-		for i, mapping := range portMappings {
-			if mapping.Host == resolution.ConflictPort {
-				portMappings[i].Host = resolution.ResolvedPort
-				g.logger.Debug(ctx, "ポートマッピング更新",
-					types.Field{Key: "service", Value: serviceName},
-					types.Field{Key: "old_port", Value: resolution.ConflictPort},
-					types.Field{Key: "new_port", Value: resolution.ResolvedPort})
-				break
-			}
+		// 対応するポートマッピング（プロトコル・host_ip も一致し、まだ更新していないもの）を検索して更新
+		i := portMappingIndex(portMappings, used, resolution.ConflictPort, resolution.Protocol, resolution.HostIP)
+		if i < 0 {
+			continue
 		}
+		used[i] = true
+		portMappings[i].Host = resolution.ResolvedPort
+		g.logger.Debug(ctx, "ポートマッピング更新",
+			types.Field{Key: "service", Value: serviceName},
+			types.Field{Key: "old_port", Value: resolution.ConflictPort},
+			types.Field{Key: "new_port", Value: resolution.ResolvedPort})
 	}
 
 	serviceOverride.Ports = portMappings
@@ -191,25 +398,25 @@ func (g *OverrideGeneratorImpl) generateServiceOverride(ctx context.Context, ser
 
 // validateServiceOverride はサービスオーバーライドの妥当性を検証します。
 func (g *OverrideGeneratorImpl) validateServiceOverride(ctx context.Context, serviceName string, serviceOverride types.ServiceOverride) error {
-	// ポートの重複チェック
+	// ポートの重複チェック（範囲指定は範囲内の全ポートを対象）
 	portMap := make(map[int]bool)
 	for _, portMapping := range serviceOverride.Ports {
-		if portMapping.Host != 0 { // ホストポートが指定されている場合のみ
-			if portMap[portMapping.Host] {
+		for _, hostPort := range portMapping.HostPorts() {
+			if portMap[hostPort] {
 				return &errors.AppError{
 					Code:    errors.ErrValidationFailed,
-					Message: fmt.Sprintf("サービス %s で重複するホストポート: %d", serviceName, portMapping.Host),
+					Message: fmt.Sprintf("サービス %s で重複するホストポート: %d", serviceName, hostPort),
 					Fields: map[string]interface{}{
 						"service":   serviceName,
-						"host_port": portMapping.Host,
+						"host_port": hostPort,
 					},
 				}
 			}
-			portMap[portMapping.Host] = true
+			portMap[hostPort] = true
 		}
 
 		// ポート範囲の検証
-		if portMapping.Host < 0 || portMapping.Host > 65535 {
+		if portMapping.Host < 0 || portMapping.Host > 65535 || portMapping.HostEnd > 65535 {
 			return &errors.AppError{
 				Code:    errors.ErrValidationFailed,
 				Message: fmt.Sprintf("無効なホストポート: %d", portMapping.Host),
@@ -263,109 +470,285 @@ func (g *OverrideGeneratorImpl) validateResolutionUniqueness(ctx context.Context
 	return nil
 }
 
-// generateOverrideYAML は!overrideタグ付きのYAMLを生成します。
-func (g *OverrideGeneratorImpl) generateOverrideYAML(override *types.OverrideConfig) string {
-	var builder strings.Builder
+// generateOverrideYAML は!overrideタグ付きのYAMLを生成します（!reset の場合はタグを付けません）。
+// yaml.v3 のノードで組み立て、マップのキーは名前順に並べるため、同じ入力からは常に同じ内容になります。
+func (g *OverrideGeneratorImpl) generateOverrideYAML(override *types.OverrideConfig) (string, error) {
+	root := mappingNode()
 
 	// プロジェクト名がある場合は先頭に出力
 	if override.Name != "" {
-		builder.WriteString(fmt.Sprintf("name: %s\n\n", override.Name))
+		appendField(root, "name", stringNode(override.Name))
 	}
 
-	builder.WriteString("services:\n")
-
-	for serviceName, serviceOverride := range override.Services {
-		builder.WriteString(fmt.Sprintf("    %s:\n", serviceName))
+	services := mappingNode()
+	for _, serviceName := range sortedKeys(override.Services) {
+		appendField(services, serviceName, g.serviceOverrideNode(override.Services[serviceName], listTag(override)))
+	}
+	appendField(root, "services", services)
 
-		if len(serviceOverride.Ports) > 0 {
-			builder.WriteString("        ports: !override\n")
-			for _, port := range serviceOverride.Ports {
-				if port.Host != 0 {
-					builder.WriteString(fmt.Sprintf("            - \"%d:%d\"\n", port.Host, port.Container))
+	if len(override.Networks) > 0 {
+		networks := mappingNode()
+		for _, netName := range sortedKeys(override.Networks) {
+			network := mappingNode()
+			if config := override.Networks[netName].IPAM.Config; len(config) > 0 {
+				configs := sequenceNode("")
+				for _, cfg := range config {
+					entry := mappingNode()
+					appendField(entry, "subnet", quotedNode(cfg.Subnet))
+					configs.Content = append(configs.Content, entry)
 				}
+				ipam := mappingNode()
+				appendField(ipam, "config", configs)
+				appendField(network, "ipam", ipam)
 			}
+			appendField(networks, netName, network)
 		}
+		appendField(root, "networks", networks)
+	}
 
-		if len(serviceOverride.Networks) > 0 {
-			builder.WriteString("        networks:\n")
-			for netName, netConfig := range serviceOverride.Networks {
-				builder.WriteString(fmt.Sprintf("            %s:\n", netName))
-				if netConfig.IPv4Address != "" {
-					builder.WriteString(fmt.Sprintf("                ipv4_address: %s\n", netConfig.IPv4Address))
-				}
+	if len(override.Volumes) > 0 {
+		volumes := mappingNode()
+		for _, volumeName := range sortedKeys(override.Volumes) {
+			volume := mappingNode()
+			appendField(volume, "name", quotedNode(override.Volumes[volumeName].Name))
+			appendField(volumes, volumeName, volume)
+		}
+		appendField(root, "volumes", volumes)
+	}
+
+	// テンプレートの設定を追加する（gopose が生成した値を優先）
+	if len(override.TemplateContent) > 0 {
+		extra, err := templateRootNode(override.TemplateContent)
+		if err != nil {
+			return "", err
+		}
+		if extra != nil {
+			mergeNode(root, extra)
+		}
+	}
+
+	// メタデータは本文の後に出力する
+	metadata, err := metadataNode(override.Metadata)
+	if err != nil {
+		return "", err
+	}
+	appendField(root, metadataKey, metadata)
+
+	var builder strings.Builder
+	encoder := yaml.NewEncoder(&builder)
+	encoder.SetIndent(4)
+	if err := encoder.Encode(root); err != nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrFileWriteFailed,
+			Message: "overrideのYAML生成に失敗しました",
+			Cause:   err,
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrFileWriteFailed,
+			Message: "overrideのYAML生成に失敗しました",
+			Cause:   err,
+		}
+	}
+	return builder.String(), nil
+}
+
+// serviceOverrideNode はサービスのオーバーライドをノードにします。置き換えるリストには tag を付けます。
+func (g *OverrideGeneratorImpl) serviceOverrideNode(serviceOverride types.ServiceOverride, tag string) *yaml.Node {
+	service := mappingNode()
+
+	if serviceOverride.ContainerName != "" {
+		appendField(service, "container_name", stringNode(serviceOverride.ContainerName))
+	}
+	if serviceOverride.NetworkMode != "" {
+		appendField(service, "network_mode", quotedNode(serviceOverride.NetworkMode))
+	}
+	// リストはマージ時に追記されるため、書き換えたものは !override（または !reset のファイル）で置き換える
+	appendOverrideStringList(service, "links", serviceOverride.Links, tag)
+	appendOverrideStringList(service, "external_links", serviceOverride.ExternalLinks, tag)
+	appendOverrideStringList(service, "volumes_from", serviceOverride.VolumesFrom, tag)
+
+	// environment はキー単位でマージされるため、書き換えたキーだけを出力する
+	if len(serviceOverride.Environment) > 0 {
+		environment := mappingNode()
+		for _, key := range sortedKeys(serviceOverride.Environment) {
+			appendField(environment, key, quotedNode(escapeInterpolation(serviceOverride.Environment[key])))
+		}
+		appendField(service, "environment", environment)
+	}
+
+	if len(serviceOverride.Ports) > 0 {
+		ports := sequenceNode(tag)
+		for _, port := range serviceOverride.Ports {
+			if port.LongSyntax {
+				// 元ファイルが長形式の場合は mode/name/app_protocol を保持するため長形式で出力
+				ports.Content = append(ports.Content, longSyntaxPortNode(port))
+			} else {
+				// ホストIP・プロトコル・コンテナポートのみのエントリも元のまま残す
+				ports.Content = append(ports.Content, quotedNode(port.ShortSyntax()))
 			}
 		}
+		appendField(service, "ports", ports)
 	}
 
-	if len(override.Networks) > 0 {
-		builder.WriteString("networks:\n")
-		for netName, netOverride := range override.Networks {
-			builder.WriteString(fmt.Sprintf("    %s:\n", netName))
-			if len(netOverride.IPAM.Config) > 0 {
-				builder.WriteString("        ipam:\n")
-				builder.WriteString("            config:\n")
-				for _, cfg := range netOverride.IPAM.Config {
-					builder.WriteString(fmt.Sprintf("                - subnet: \"%s\"\n", cfg.Subnet))
-				}
+	if len(serviceOverride.Networks) > 0 {
+		networks := mappingNode()
+		for _, netName := range sortedKeys(serviceOverride.Networks) {
+			network := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""}
+			if ipv4Address := serviceOverride.Networks[netName].IPv4Address; ipv4Address != "" {
+				network = mappingNode()
+				appendField(network, "ipv4_address", stringNode(ipv4Address))
 			}
+			appendField(networks, netName, network)
 		}
+		appendField(service, "networks", networks)
 	}
 
-	return builder.String()
+	return service
+}
+
+// longSyntaxPortNode は長形式のポートエントリをノードにします。
+func longSyntaxPortNode(port types.PortMapping) *yaml.Node {
+	entry := mappingNode()
+	appendField(entry, "target", intNode(port.Container))
+	if port.Host != 0 {
+		appendField(entry, "published", quotedNode(port.Published()))
+	}
+	if port.HostIP != "" {
+		appendField(entry, "host_ip", quotedNode(port.HostIP))
+	}
+	if port.Protocol != "" {
+		appendField(entry, "protocol", stringNode(port.Protocol))
+	}
+	if port.Mode != "" {
+		appendField(entry, "mode", stringNode(port.Mode))
+	}
+	if port.Name != "" {
+		appendField(entry, "name", stringNode(port.Name))
+	}
+	if port.AppProtocol != "" {
+		appendField(entry, "app_protocol", stringNode(port.AppProtocol))
+	}
+	return entry
+}
+
+// appendOverrideStringList は空でない文字列リストを tag（通常は !override）付きで追加します。
+func appendOverrideStringList(mapping *yaml.Node, key string, values []string, tag string) {
+	if len(values) == 0 {
+		return
+	}
+	list := sequenceNode(tag)
+	for _, value := range values {
+		list.Content = append(list.Content, quotedNode(value))
+	}
+	appendField(mapping, key, list)
+}
+
+// escapeInterpolation は変数展開後の値がComposeに再度展開されないよう $ を $$ にエスケープします。
+func escapeInterpolation(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
 }
 
 // generateFileHeader はファイルヘッダーコメントを生成します。
-func (g *OverrideGeneratorImpl) generateFileHeader() string {
+// command が空の場合はComposeが自動で読み込むoverrideとして、それ以外は -f で指定する実行コマンドを案内します。
+func (g *OverrideGeneratorImpl) generateFileHeader(command string) string {
+	usage := `# To use this override:
+# 1. Keep this file in the same directory as your docker-compose.yml
+# 2. Run: docker compose up
+# 
+# Docker Compose will automatically merge both files.`
+	if command != "" {
+		usage = fmt.Sprintf(`# Docker Compose does not load this file automatically. To use this override, run:
+#   %s`, command)
+	}
+
 	return fmt.Sprintf(`# Docker Compose Override File
-# Generated by gopose (Go Port Override Solution Engine)
-# Generated at: %s
+# %s (Go Port Override Solution Engine)
 # 
 # This file contains port mappings to resolve conflicts detected in your
 # original docker-compose.yml file. The original file remains unchanged.
 # 
-# To use this override:
-# 1. Keep this file in the same directory as your docker-compose.yml
-# 2. Run: docker-compose up
-# 
-# Docker Compose will automatically merge both files.
+%s
 # 
 # WARNING: This file is auto-generated. Manual changes may be overwritten.
 
-`, time.Now().Format(time.RFC3339))
+`, generatedByMarker, usage)
 }
 
 // OverrideTemplateGeneratorImpl はテンプレートベースのOverride生成実装です。
+// テンプレートは text/template で描画し、解決後のポートなどはヘルパー関数（templateFuncs）で参照できます。
 type OverrideTemplateGeneratorImpl struct {
+	engine *TextTemplateEngine
 	logger logger.Logger
 }
 
 // NewOverrideTemplateGeneratorImpl は新しいOverrideTemplateGeneratorImplを作成します。
 func NewOverrideTemplateGeneratorImpl(logger logger.Logger) *OverrideTemplateGeneratorImpl {
 	return &OverrideTemplateGeneratorImpl{
+		engine: NewTextTemplateEngine(logger),
 		logger: logger,
 	}
 }
 
+// Engine はテンプレートの描画に使うエンジンを返します（関数を追加登録する場合に使います）。
+func (t *OverrideTemplateGeneratorImpl) Engine() *TextTemplateEngine {
+	return t.engine
+}
+
+// RenderTemplate はテンプレートを描画し、生成したoverrideにマージする設定（YAML）を返します。
+func (t *OverrideTemplateGeneratorImpl) RenderTemplate(ctx context.Context, templatePath string, data GenerationData) ([]byte, error) {
+	t.logger.Debug(ctx, "テンプレートの描画開始",
+		types.Field{Key: "template_path", Value: templatePath})
+
+	templateContent, err := t.engine.LoadTemplate(ctx, templatePath)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := t.engine.Render(ctx, templatePath, templateContent, data, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// マージできる形式かを描画時に確認する
+	if _, err := templateRootNode([]byte(rendered)); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			appErr.Fields = map[string]interface{}{"template_path": templatePath}
+		}
+		return nil, err
+	}
+
+	t.logger.Info(ctx, fmt.Sprintf("テンプレート %s を描画しました", templatePath),
+		types.Field{Key: "template_path", Value: templatePath},
+		types.Field{Key: "size", Value: len(rendered)})
+
+	return []byte(rendered), nil
+}
+
 // GenerateFromTemplate はテンプレートからOverrideを生成します。
+// data が GenerationData の場合はヘルパー関数が解決後の値を参照します。
 func (t *OverrideTemplateGeneratorImpl) GenerateFromTemplate(ctx context.Context, templatePath string, data interface{}) (*types.OverrideConfig, error) {
 	t.logger.Debug(ctx, "テンプレートからOverride生成開始",
 		types.Field{Key: "template_path", Value: templatePath})
 
-	// テンプレートファイルの読み込み
-	templateContent, err := os.ReadFile(templatePath)
-	if err != nil {
-		return nil, &errors.AppError{
-			Code:    errors.ErrFileReadFailed,
-			Message: fmt.Sprintf("テンプレートファイル読み込みに失敗: %s", templatePath),
-			Cause:   err,
-			Fields: map[string]interface{}{
-				"template_path": templatePath,
-			},
+	var generation GenerationData
+	switch d := data.(type) {
+	case GenerationData:
+		generation = d
+	case *GenerationData:
+		if d != nil {
+			generation = *d
 		}
 	}
 
-	// 簡単なテンプレート処理（実際の実装ではtext/templateを使用することを推奨）
-	processedContent := string(templateContent)
+	templateContent, err := t.engine.LoadTemplate(ctx, templatePath)
+	if err != nil {
+		return nil, err
+	}
+	processedContent, err := t.engine.Render(ctx, templatePath, templateContent, data, generation)
+	if err != nil {
+		return nil, err
+	}
 
 	// YAMLとして解析
 	var override types.OverrideConfig
@@ -379,6 +762,7 @@ func (t *OverrideTemplateGeneratorImpl) GenerateFromTemplate(ctx context.Context
 			},
 		}
 	}
+	override.TemplateContent = []byte(processedContent)
 
 	t.logger.Info(ctx, "テンプレートからOverride生成完了",
 		types.Field{Key: "services_count", Value: len(override.Services)})
@@ -409,26 +793,13 @@ func (t *OverrideTemplateGeneratorImpl) ValidateTemplate(ctx context.Context, te
 			types.Field{Key: "extension", Value: ext})
 	}
 
-	// 基本的なYAML構文チェック
-	content, err := os.ReadFile(templatePath)
+	// テンプレートの構文チェック（YAMLとしての妥当性は描画後に確認する）
+	content, err := t.engine.LoadTemplate(ctx, templatePath)
 	if err != nil {
-		return &errors.AppError{
-			Code:    errors.ErrFileReadFailed,
-			Message: "テンプレートファイル読み込みに失敗",
-			Cause:   err,
-		}
+		return err
 	}
-
-	var tempData interface{}
-	if err := yaml.Unmarshal(content, &tempData); err != nil {
-		return &errors.AppError{
-			Code:    errors.ErrValidationFailed,
-			Message: "テンプレートのYAML構文が無効です",
-			Cause:   err,
-			Fields: map[string]interface{}{
-				"template_path": templatePath,
-			},
-		}
+	if err := t.engine.Parse(templatePath, content); err != nil {
+		return err
 	}
 
 	t.logger.Info(ctx, "テンプレート検証完了")
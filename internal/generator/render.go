@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/file"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// RenderedFile はテンプレートから描画した設定ファイルです（!reset のファイルなど、テンプレートを使わずに生成したものは Template が空です）。
+type RenderedFile struct {
+	Template string
+	Output   string
+	Content  []byte
+}
+
+// Record はoverrideのメタデータに記録する生成ファイルの情報を返します。
+func (f RenderedFile) Record() types.GeneratedFile {
+	return types.GeneratedFile{
+		Path:     f.Output,
+		Template: f.Template,
+		SHA256:   sha256Hex(f.Content),
+	}
+}
+
+// ResolveTemplateFiles はテンプレートと出力先の相対パスを baseDir（Composeファイルのディレクトリ）基準に解決します。
+func ResolveTemplateFiles(templates []types.TemplateFile, baseDir string) []types.TemplateFile {
+	resolved := make([]types.TemplateFile, 0, len(templates))
+	for _, template := range templates {
+		if template.Template != "" && !filepath.IsAbs(template.Template) {
+			template.Template = filepath.Join(baseDir, template.Template)
+		}
+		if template.Output != "" && !filepath.IsAbs(template.Output) {
+			template.Output = filepath.Join(baseDir, template.Output)
+		}
+		resolved = append(resolved, template)
+	}
+	return resolved
+}
+
+// RenderFiles はテンプレートを描画します（ファイルへの書き込みは WriteRenderedFiles で行います）。
+// テンプレートでは port・url などのヘルパー関数で解決後のポートを参照できます。
+func (t *OverrideTemplateGeneratorImpl) RenderFiles(ctx context.Context, templates []types.TemplateFile, data GenerationData) ([]RenderedFile, error) {
+	outputs := make(map[string]string)
+	rendered := make([]RenderedFile, 0, len(templates))
+	for _, template := range templates {
+		if template.Template == "" || template.Output == "" {
+			return nil, &errors.AppError{
+				Code:    errors.ErrConfigInvalid,
+				Message: fmt.Sprintf("テンプレートと出力先の両方を指定してください（template: %q, output: %q）", template.Template, template.Output),
+			}
+		}
+		if previous, exists := outputs[template.Output]; exists {
+			return nil, &errors.AppError{
+				Code:    errors.ErrConfigInvalid,
+				Message: fmt.Sprintf("テンプレート %s と %s の出力先が同じです: %s", previous, template.Template, template.Output),
+			}
+		}
+		outputs[template.Output] = template.Template
+
+		content, err := t.engine.LoadTemplate(ctx, template.Template)
+		if err != nil {
+			return nil, err
+		}
+		output, err := t.engine.Render(ctx, template.Template, content, data, data)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, RenderedFile{
+			Template: template.Template,
+			Output:   template.Output,
+			Content:  []byte(output),
+		})
+	}
+	return rendered, nil
+}
+
+// CheckRenderedFiles は描画したファイルを書き込めるかを確認します。
+// 前回 gopose が生成したファイル（previous）以外が出力先に存在する場合や、生成後に編集されている場合は、
+// force でない限りエラーを返します。overrideを書き込む前に呼び出し、途中まで書き込んだ状態を避けます。
+func (t *OverrideTemplateGeneratorImpl) CheckRenderedFiles(ctx context.Context, files []RenderedFile, previous []types.GeneratedFile, force bool) error {
+	if force {
+		return nil
+	}
+	for _, rendered := range files {
+		exists, edited, err := CheckGeneratedFile(rendered.Output, previous)
+		if err != nil {
+			return err
+		}
+		if exists && edited {
+			return overwriteError(rendered.Output, previous)
+		}
+	}
+	return nil
+}
+
+// WriteRenderedFiles は描画したファイルを原子的に書き込みます。既存のファイルは opts に応じてバックアップします。
+func (t *OverrideTemplateGeneratorImpl) WriteRenderedFiles(ctx context.Context, files []RenderedFile, previous []types.GeneratedFile, opts WriteOptions) error {
+	writer := file.NewAtomicWriterImpl(t.logger)
+	for _, rendered := range files {
+		exists, edited, err := CheckGeneratedFile(rendered.Output, previous)
+		if err != nil {
+			return err
+		}
+		if edited && !opts.Force {
+			return overwriteError(rendered.Output, previous)
+		}
+		if _, err := backupBeforeWrite(ctx, t.logger, rendered.Output, exists, edited, opts); err != nil {
+			return err
+		}
+		if err := writer.WriteAtomic(ctx, rendered.Output, rendered.Content); err != nil {
+			return err
+		}
+		cleanupBackups(ctx, t.logger, rendered.Output, opts)
+
+		message := fmt.Sprintf("%s を %s から生成しました", rendered.Output, rendered.Template)
+		if rendered.Template == "" {
+			message = fmt.Sprintf("%s を生成しました", rendered.Output)
+		}
+		t.logger.Info(ctx, message,
+			types.Field{Key: "output", Value: rendered.Output},
+			types.Field{Key: "template", Value: rendered.Template})
+	}
+	return nil
+}
+
+// overwriteError は出力先を上書きできない理由に応じたエラーを返します。
+func overwriteError(path string, previous []types.GeneratedFile) error {
+	for _, generated := range previous {
+		if generated.Path == path {
+			return manualEditError(path)
+		}
+	}
+	return &errors.AppError{
+		Code:    errors.ErrFileModified,
+		Message: fmt.Sprintf("%s は gopose が生成したファイルではないため上書きしません。--force で上書きできます（既存の内容はバックアップされます）", path),
+		Fields: map[string]interface{}{
+			"file_path": path,
+		},
+	}
+}
+
+// CheckGeneratedFile はファイルの有無と、前回生成した内容から変更されているか（生成したものでない場合を含む）を返します。
+func CheckGeneratedFile(path string, previous []types.GeneratedFile) (bool, bool, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("ファイル読み込みに失敗しました: %s", path),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"file_path": path,
+			},
+		}
+	}
+	for _, generated := range previous {
+		if generated.Path == path {
+			return true, generated.SHA256 != sha256Hex(content), nil
+		}
+	}
+	return true, true, nil
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/internal/parser"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// verifyPortRoundTrip は出力したoverrideを解析し直し、各サービスのポートが override の内容と一致するかを検証します。
+// ホストIP・プロトコル・ホストポートの無いエントリなどが出力時に失われていないことを確認します。
+func (g *OverrideGeneratorImpl) verifyPortRoundTrip(ctx context.Context, override *types.OverrideConfig, content []byte) error {
+	reparsed, err := parser.NewYamlComposeParser(&logger.NopLogger{}).ParseFromBytes(ctx, content)
+	if err != nil {
+		return &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: "生成したoverrideの再解析に失敗しました",
+			Cause:   err,
+		}
+	}
+
+	for serviceName, serviceOverride := range override.Services {
+		if len(serviceOverride.Ports) == 0 {
+			continue
+		}
+
+		actual := reparsed.Services[serviceName].Ports
+		if len(actual) != len(serviceOverride.Ports) {
+			return &errors.AppError{
+				Code: errors.ErrValidationFailed,
+				Message: fmt.Sprintf("生成したoverrideのサービス %s のポート数が一致しません（期待: %d、出力: %d）",
+					serviceName, len(serviceOverride.Ports), len(actual)),
+				Fields: map[string]interface{}{
+					"service": serviceName,
+				},
+			}
+		}
+		for i, expected := range serviceOverride.Ports {
+			if !samePortMapping(expected, actual[i]) {
+				return &errors.AppError{
+					Code: errors.ErrValidationFailed,
+					Message: fmt.Sprintf("生成したoverrideのサービス %s のポートが一致しません（期待: %s、出力: %s）",
+						serviceName, expected.ShortSyntax(), actual[i].ShortSyntax()),
+					Fields: map[string]interface{}{
+						"service": serviceName,
+						"index":   i,
+					},
+				}
+			}
+		}
+	}
+
+	g.logger.Debug(ctx, "overrideのポートの往復検証完了")
+	return nil
+}
+
+// samePortMapping は定義元を除いたポートマッピングの内容が等しいかを返します（プロトコル省略は tcp とみなします）。
+func samePortMapping(a, b types.PortMapping) bool {
+	protocol := func(m types.PortMapping) string {
+		if m.Protocol == "" {
+			return "tcp"
+		}
+		return m.Protocol
+	}
+	return a.Host == b.Host &&
+		a.HostEnd == b.HostEnd &&
+		a.Container == b.Container &&
+		a.HostIP == b.HostIP &&
+		protocol(a) == protocol(b) &&
+		a.Mode == b.Mode &&
+		a.Name == b.Name &&
+		a.AppProtocol == b.AppProtocol
+}
@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/scanner"
+	"github.com/harakeishi/gopose/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ListSyntax はoverrideで元のリスト（ports など）を置き換える記法です。
+type ListSyntax string
+
+const (
+	// ListSyntaxOverride はリストに !override を付けて置き換えます（Compose 2.24.4 以降）。
+	ListSyntaxOverride ListSyntax = "override"
+	// ListSyntaxReset はoverrideより先に読み込むファイルの !reset で元のリストを取り除き、
+	// overrideで追加し直します（Compose 2.24.0 以降）。
+	ListSyntaxReset ListSyntax = "reset"
+)
+
+// ListSyntaxForComposeVersion はComposeのバージョンで使えるリストの置き換え方を返します。
+// !reset にも対応していないバージョンでは、元のポートを残さずに置き換える方法が無いためエラーを返します。
+func ListSyntaxForComposeVersion(version scanner.ComposeVersion) (ListSyntax, error) {
+	switch {
+	case version.AtLeast(2, 24, 4):
+		return ListSyntaxOverride, nil
+	case version.AtLeast(2, 24, 0):
+		return ListSyntaxReset, nil
+	default:
+		return "", &errors.AppError{
+			Code: errors.ErrValidationFailed,
+			Message: fmt.Sprintf("Docker Compose %s は !override・!reset に対応していないため、元のポートを置き換えるoverrideを生成できません。"+
+				"Docker Compose 2.24.4 以降（最低でも 2.24.0）に更新してください", version),
+			Fields: map[string]interface{}{
+				"compose_version": version.String(),
+			},
+		}
+	}
+}
+
+// ListSyntaxForFormat は出力形式で使えるリストの置き換え方を返します。
+// JSONには !override を書けないため、!reset のファイル（YAML）で元のリストを取り除く方法に限られます（Compose 2.24.0 以降）。
+func ListSyntaxForFormat(syntax ListSyntax, format OverrideFormat) ListSyntax {
+	if format == OverrideFormatJSON {
+		return ListSyntaxReset
+	}
+	return syntax
+}
+
+// NeedsListReplacement はoverrideに元のリストを置き換える項目（ports・links など）があるかを返します。
+func NeedsListReplacement(override *types.OverrideConfig) bool {
+	for _, serviceOverride := range override.Services {
+		if len(resetKeys(serviceOverride)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ResetFilePath は !reset を書き込むファイルのパスを返します（docker-compose.override.yml → docker-compose.override.reset.yml）。
+// !reset はYAMLでしか書けないため、.json の出力先では .reset.yml とします。
+func ResetFilePath(outputPath string) string {
+	ext := filepath.Ext(outputPath)
+	resetExt := ext
+	if OverrideFormatFromPath(outputPath) == OverrideFormatJSON {
+		resetExt = ".yml"
+	}
+	return strings.TrimSuffix(outputPath, ext) + ".reset" + resetExt
+}
+
+// RenderResetFile はoverrideで置き換えるリストを !reset で取り除くファイルの内容を生成します（対象が無い場合は nil）。
+// Composeではこのファイルを元のファイルの後、overrideの前に指定します。
+// !reset はJSONでは書けないため、常にYAML形式で生成します。
+func (g *OverrideGeneratorImpl) RenderResetFile(ctx context.Context, override *types.OverrideConfig) ([]byte, error) {
+	services := mappingNode()
+	for _, serviceName := range sortedKeys(override.Services) {
+		keys := resetKeys(override.Services[serviceName])
+		if len(keys) == 0 {
+			continue
+		}
+		service := mappingNode()
+		for _, key := range keys {
+			appendField(service, key, sequenceNode(resetTag))
+		}
+		appendField(services, serviceName, service)
+	}
+	if len(services.Content) == 0 {
+		return nil, nil
+	}
+
+	root := mappingNode()
+	appendField(root, "services", services)
+	var builder strings.Builder
+	encoder := yaml.NewEncoder(&builder)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileWriteFailed,
+			Message: "!reset のファイルの生成に失敗しました",
+			Cause:   err,
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileWriteFailed,
+			Message: "!reset のファイルの生成に失敗しました",
+			Cause:   err,
+		}
+	}
+	content := fmt.Sprintf("# %s\n# Removes the lists that the override re-adds. Load this file before the override.\n\n", generatedByMarker) + builder.String()
+
+	g.logger.Debug(ctx, "!reset のファイルを生成しました",
+		types.Field{Key: "services", Value: len(services.Content) / 2})
+
+	return []byte(content), nil
+}
+
+// resetKeys はサービスのoverrideのうち、元のリストを置き換える項目のキーを返します。
+func resetKeys(serviceOverride types.ServiceOverride) []string {
+	var keys []string
+	if len(serviceOverride.Links) > 0 {
+		keys = append(keys, "links")
+	}
+	if len(serviceOverride.ExternalLinks) > 0 {
+		keys = append(keys, "external_links")
+	}
+	if len(serviceOverride.VolumesFrom) > 0 {
+		keys = append(keys, "volumes_from")
+	}
+	if len(serviceOverride.Ports) > 0 {
+		keys = append(keys, "ports")
+	}
+	return keys
+}
+
+// listTag はoverrideのリストに付けるタグを返します（!reset の場合は別のファイルで取り除くため付けません）。
+func listTag(override *types.OverrideConfig) string {
+	if ListSyntax(override.Metadata.ListSyntax) == ListSyntaxReset {
+		return ""
+	}
+	return overrideTag
+}
@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"text/template"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// TextTemplateEngine は text/template でテンプレートを描画する TemplateEngine の実装です。
+// 解決後のポート・サブネットなどを参照するヘルパー関数（templateFuncs）を利用できます。
+type TextTemplateEngine struct {
+	funcs  template.FuncMap
+	logger logger.Logger
+}
+
+// NewTextTemplateEngine は新しいTextTemplateEngineを作成します。
+func NewTextTemplateEngine(logger logger.Logger) *TextTemplateEngine {
+	return &TextTemplateEngine{
+		funcs:  template.FuncMap{},
+		logger: logger,
+	}
+}
+
+// RegisterFunction はテンプレートで使える関数を追加します。組み込みのヘルパーと同名の場合は上書きします。
+func (e *TextTemplateEngine) RegisterFunction(name string, fn interface{}) error {
+	if name == "" || fn == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: fmt.Sprintf("テンプレート関数として登録できません: %q", name),
+			Fields: map[string]interface{}{
+				"name": name,
+			},
+		}
+	}
+	e.funcs[name] = fn
+	return nil
+}
+
+// LoadTemplate はテンプレートファイルを読み込みます。
+func (e *TextTemplateEngine) LoadTemplate(ctx context.Context, templateName string) (string, error) {
+	content, err := os.ReadFile(templateName)
+	if err != nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: fmt.Sprintf("テンプレートファイル読み込みに失敗: %s", templateName),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"template_path": templateName,
+			},
+		}
+	}
+	return string(content), nil
+}
+
+// RenderOverride は生成データを渡してテンプレートを描画します。
+func (e *TextTemplateEngine) RenderOverride(ctx context.Context, tmpl string, data GenerationData) (string, error) {
+	return e.render(ctx, "override", tmpl, data, data)
+}
+
+// Render は任意のデータを渡してテンプレートを描画します。ヘルパー関数は generation の内容を参照します。
+func (e *TextTemplateEngine) Render(ctx context.Context, name, tmpl string, dot interface{}, generation GenerationData) (string, error) {
+	return e.render(ctx, name, tmpl, dot, generation)
+}
+
+// Parse はテンプレートの構文と、使用している関数が定義されているかを検証します。
+func (e *TextTemplateEngine) Parse(name, tmpl string) error {
+	_, err := e.parse(name, tmpl, GenerationData{})
+	return err
+}
+
+func (e *TextTemplateEngine) render(ctx context.Context, name, tmpl string, dot interface{}, generation GenerationData) (string, error) {
+	parsed, err := e.parse(name, tmpl, generation)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := parsed.Execute(&buf, dot); err != nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: fmt.Sprintf("テンプレートの描画に失敗しました: %s", name),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"template": name,
+			},
+		}
+	}
+
+	e.logger.Debug(ctx, "テンプレートを描画しました",
+		types.Field{Key: "template", Value: name},
+		types.Field{Key: "size", Value: buf.Len()})
+
+	return buf.String(), nil
+}
+
+func (e *TextTemplateEngine) parse(name, tmpl string, generation GenerationData) (*template.Template, error) {
+	funcs := templateFuncs(generation)
+	for fnName, fn := range e.funcs {
+		funcs[fnName] = fn
+	}
+
+	// 存在しないキーの参照は空文字列ではなくエラーにする
+	parsed, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: fmt.Sprintf("テンプレートの解析に失敗しました: %s", name),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"template": name,
+			},
+		}
+	}
+	return parsed, nil
+}
+
+// NewGenerationData は生成したoverrideと元の設定からテンプレートに渡すデータを作成します。
+// Services にはoverrideで変更したサービスの元の定義が名前順に入ります。
+func NewGenerationData(config *types.ComposeConfig, override *types.OverrideConfig) GenerationData {
+	data := GenerationData{
+		OriginalConfig: config,
+		Override:       override,
+	}
+	if override == nil {
+		return data
+	}
+
+	data.Resolutions = override.Metadata.Resolutions
+	data.Metadata = &override.Metadata
+	if config != nil {
+		for _, serviceName := range sortedKeys(override.Services) {
+			if service, exists := config.Services[serviceName]; exists {
+				if service.Name == "" {
+					service.Name = serviceName
+				}
+				data.Services = append(data.Services, service)
+			}
+		}
+	}
+	return data
+}
+
+// templateFuncs はテンプレートで使えるヘルパー関数を返します。
+//
+//	port "db" 5432     サービスのコンテナポート（無ければホストポート）に対応する、解決後のホストポート
+//	url "web" 80       ホストからサービスに接続するURL（http://localhost:<解決後のホストポート>）
+//	ip "db" "backend"  サービスのネットワーク上の固定IP（解決後）
+//	subnet "backend"   ネットワークのサブネット（解決後）
+//	project            Composeのプロジェクト名
+//	resolved "db"      サービスのポートを付け替えたかどうか
+func templateFuncs(data GenerationData) template.FuncMap {
+	port := func(service string, port int) (int, error) {
+		for _, mapping := range effectivePorts(data, service) {
+			if mapping.Container == port && mapping.Host != 0 {
+				return mapping.Host, nil
+			}
+		}
+		for _, mapping := range effectivePorts(data, service) {
+			if mapping.Host == port {
+				return mapping.Host, nil
+			}
+		}
+		for _, resolution := range data.Resolutions {
+			if resolution.Service == service && resolution.OriginalPort == port {
+				return resolution.ResolvedPort, nil
+			}
+		}
+		return 0, fmt.Errorf("サービス %s はポート %d を公開していません", service, port)
+	}
+
+	return template.FuncMap{
+		"port": port,
+		"url": func(service string, containerPort int) (string, error) {
+			hostPort, err := port(service, containerPort)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("http://localhost:%d", hostPort), nil
+		},
+		"ip": func(service, network string) (string, error) {
+			if data.Override != nil {
+				if serviceNetwork, exists := data.Override.Services[service].Networks[network]; exists && serviceNetwork.IPv4Address != "" {
+					return serviceNetwork.IPv4Address, nil
+				}
+			}
+			if data.OriginalConfig != nil {
+				if serviceNetwork, exists := data.OriginalConfig.Services[service].Networks[network]; exists && serviceNetwork.IPv4Address != "" {
+					return serviceNetwork.IPv4Address, nil
+				}
+			}
+			return "", fmt.Errorf("サービス %s にはネットワーク %s の固定IPがありません", service, network)
+		},
+		"subnet": func(network string) (string, error) {
+			if data.Override != nil {
+				if config := data.Override.Networks[network].IPAM.Config; len(config) > 0 && config[0].Subnet != "" {
+					return config[0].Subnet, nil
+				}
+			}
+			if data.OriginalConfig != nil {
+				if config := data.OriginalConfig.Networks[network].IPAM.Config; len(config) > 0 && config[0].Subnet != "" {
+					return config[0].Subnet, nil
+				}
+			}
+			return "", fmt.Errorf("ネットワーク %s にはサブネットが指定されていません", network)
+		},
+		"project": func() string {
+			if data.Metadata != nil && data.Metadata.Project != "" {
+				return data.Metadata.Project
+			}
+			if data.Override != nil {
+				return data.Override.Name
+			}
+			return ""
+		},
+		"resolved": func(service string) bool {
+			for _, resolution := range data.Resolutions {
+				if resolution.Service == service {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// effectivePorts はoverrideを適用した後のサービスのポートを返します。
+func effectivePorts(data GenerationData, service string) []types.PortMapping {
+	if data.Override != nil {
+		if ports := data.Override.Services[service].Ports; len(ports) > 0 {
+			return ports
+		}
+	}
+	if data.OriginalConfig != nil {
+		return data.OriginalConfig.Services[service].Ports
+	}
+	return nil
+}
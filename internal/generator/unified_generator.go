@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/harakeishi/gopose/internal/errors"
 	"github.com/harakeishi/gopose/internal/logger"
 	"github.com/harakeishi/gopose/internal/scanner"
 	"github.com/harakeishi/gopose/pkg/types"
@@ -36,9 +37,10 @@ func (u *UnifiedOverrideGeneratorImpl) GenerateFromConflicts(ctx context.Context
 		Version:  config.Version,
 		Services: make(map[string]types.ServiceOverride),
 		Networks: make(map[string]types.NetworkOverride),
+		Volumes:  make(map[string]types.VolumeOverride),
 		Metadata: types.OverrideMetadata{
-			GeneratedAt: time.Now(),
-			Version:     "1.0.0",
+			GeneratedBy: generatedByMarker,
+			GeneratedAt: time.Now().UTC().Truncate(time.Second),
 			Resolutions: []types.ConflictResolution{}, // 統一的解決情報から変換
 		},
 	}
@@ -53,6 +55,12 @@ func (u *UnifiedOverrideGeneratorImpl) GenerateFromConflicts(ctx context.Context
 		return nil, fmt.Errorf("ネットワークオーバーライド生成に失敗: %w", err)
 	}
 
+	// コンテナ名の付け替えと参照の書き換え
+	u.generateContainerNameOverrides(ctx, config, conflictInfo.ContainerNameConflicts, override)
+
+	// 共有ボリュームの名前の付け替え
+	u.generateVolumeOverrides(conflictInfo.VolumeConflicts, override)
+
 	// メタデータに解決情報を追加
 	u.populateMetadata(conflictInfo, override)
 
@@ -75,6 +83,12 @@ func (u *UnifiedOverrideGeneratorImpl) ResolveConflicts(ctx context.Context, con
 		return fmt.Errorf("ネットワーク衝突解決に失敗: %w", err)
 	}
 
+	// コンテナ名衝突の解決
+	u.resolveContainerNameConflicts(ctx, conflictInfo.ContainerNameConflicts)
+
+	// ボリューム衝突の解決
+	u.resolveVolumeConflicts(ctx, conflictInfo.VolumeConflicts)
+
 	return nil
 }
 
@@ -102,16 +116,28 @@ func (u *UnifiedOverrideGeneratorImpl) generatePortOverrides(ctx context.Context
 		}
 		copy(serviceOverride.Ports, originalService.Ports)
 
-		// 解決済みポートで更新
+		// 解決済みポートで更新（同じホストポートでもプロトコル・host_ip が異なるエントリは別のバインド）
+		used := make([]bool, len(serviceOverride.Ports))
 		for _, conflict := range conflicts {
-			if conflict.Resolution != nil {
-				for i, mapping := range serviceOverride.Ports {
-					if mapping.Host == conflict.Port {
-						serviceOverride.Ports[i].Host = conflict.Resolution.ResolvedPort
-						break
-					}
+			if conflict.Resolution == nil {
+				continue
+			}
+			i := portMappingIndex(serviceOverride.Ports, used, conflict.Port, conflict.Protocol, conflict.HostIP)
+			if i < 0 {
+				return &errors.AppError{
+					Code:    errors.ErrValidationFailed,
+					Message: fmt.Sprintf("サービス %s に解決したポート %d/%s に対応するポート設定が見つかりません", serviceName, conflict.Port, protocolOrTCP(conflict.Protocol)),
+					Fields: map[string]interface{}{
+						"service": serviceName,
+						"port":    conflict.Port,
+					},
 				}
 			}
+			used[i] = true
+			serviceOverride.Ports[i].Host = conflict.Resolution.ResolvedPort
+			if serviceOverride.Ports[i].IsRange() {
+				serviceOverride.Ports[i].HostEnd = conflict.Resolution.ResolvedPortEnd
+			}
 		}
 
 		override.Services[serviceName] = serviceOverride
@@ -120,6 +146,28 @@ func (u *UnifiedOverrideGeneratorImpl) generatePortOverrides(ctx context.Context
 	return nil
 }
 
+// portMappingIndex は衝突したホストポート・プロトコル・host_ip に一致する、未使用のポート設定の位置を返します（無い場合は -1）。
+// 1つのエントリに複数の解決を適用しないよう、used で適用済みのエントリを除きます。
+func portMappingIndex(ports []types.PortMapping, used []bool, port int, protocol, hostIP string) int {
+	for i, mapping := range ports {
+		if used[i] || mapping.Host != port {
+			continue
+		}
+		if protocolOrTCP(mapping.Protocol) == protocolOrTCP(protocol) && mapping.HostIP == hostIP {
+			return i
+		}
+	}
+	return -1
+}
+
+// protocolOrTCP はプロトコルを返します（省略時は tcp）。
+func protocolOrTCP(protocol string) string {
+	if protocol == "" {
+		return "tcp"
+	}
+	return protocol
+}
+
 // generateNetworkOverrides はネットワーク衝突のオーバーライドを生成します。
 func (u *UnifiedOverrideGeneratorImpl) generateNetworkOverrides(ctx context.Context, config *types.ComposeConfig, networkConflicts []types.NetworkConflictInfo, override *types.OverrideConfig) error {
 	for _, conflict := range networkConflicts {
@@ -172,7 +220,10 @@ func (u *UnifiedOverrideGeneratorImpl) populateMetadata(conflictInfo *types.Unif
 			resolution := types.ConflictResolution{
 				ServiceName:  conflict.ServiceName,
 				Service:      conflict.Service,
+				OriginalPort: conflict.Port,
 				ConflictPort: conflict.Port,
+				Protocol:     conflict.Protocol,
+				HostIP:       conflict.HostIP,
 				ResolvedPort: conflict.Resolution.ResolvedPort,
 				Strategy:     conflict.Resolution.Strategy,
 				Reason:       conflict.Resolution.Reason,
@@ -182,7 +233,22 @@ func (u *UnifiedOverrideGeneratorImpl) populateMetadata(conflictInfo *types.Unif
 		}
 	}
 
+	// ネットワーク解決情報を変換
+	var networkResolutions []types.NetworkResolution
+	for _, conflict := range conflictInfo.NetworkConflicts {
+		if conflict.Resolution != nil {
+			networkResolutions = append(networkResolutions, types.NetworkResolution{
+				Network:        conflict.NetworkName,
+				OriginalSubnet: conflict.OriginalSubnet,
+				ResolvedSubnet: conflict.Resolution.ResolvedSubnet,
+				ServiceIPs:     conflict.Resolution.ServiceIPs,
+				Reason:         conflict.Resolution.Reason,
+			})
+		}
+	}
+
 	override.Metadata.Resolutions = resolutions
+	override.Metadata.NetworkResolutions = networkResolutions
 }
 
 // resolvePortConflicts はポート衝突を解決します。
@@ -205,11 +271,17 @@ func (u *UnifiedOverrideGeneratorImpl) resolvePortConflicts(ctx context.Context,
 			Reserved:          append(allocatedPorts, portConfig.Reserved...),
 		}
 
-		allocatedPort, err := u.portAllocator.AllocatePort(ctx, config)
+		// 範囲指定の場合は同じ幅の連続したポートを割り当てる
+		size := 1
+		if conflict.PortEnd > conflict.Port {
+			size = conflict.PortEnd - conflict.Port + 1
+		}
+
+		allocatedPort, err := u.portAllocator.AllocatePortBlock(ctx, size, config)
 		if err != nil {
 			// 元のポート+1での検索に失敗した場合は、設定された範囲の最初から検索
 			config.Range.Start = portConfig.Range.Start
-			allocatedPort, err = u.portAllocator.AllocatePort(ctx, config)
+			allocatedPort, err = u.portAllocator.AllocatePortBlock(ctx, size, config)
 			if err != nil {
 				u.logger.Warn(ctx, "適切な代替ポートが見つかりません",
 					types.Field{Key: "service", Value: conflict.ServiceName},
@@ -224,9 +296,16 @@ func (u *UnifiedOverrideGeneratorImpl) resolvePortConflicts(ctx context.Context,
 			Strategy:     strategy,
 			Reason:       fmt.Sprintf("ポート %d から %d への自動変更", conflict.Port, allocatedPort),
 		}
+		if size > 1 {
+			conflict.Resolution.ResolvedPortEnd = allocatedPort + size - 1
+			conflict.Resolution.Reason = fmt.Sprintf("ポート範囲 %d-%d から %d-%d への自動変更",
+				conflict.Port, conflict.PortEnd, allocatedPort, conflict.Resolution.ResolvedPortEnd)
+		}
 
 		// 次の割り当てのために予約済みポートに追加
-		allocatedPorts = append(allocatedPorts, allocatedPort)
+		for port := allocatedPort; port < allocatedPort+size; port++ {
+			allocatedPorts = append(allocatedPorts, port)
+		}
 
 		u.logger.Info(ctx, "ポート衝突解決",
 			types.Field{Key: "service", Value: conflict.ServiceName},
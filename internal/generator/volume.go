@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// resolveVolumeConflicts は名前が固定された共有ボリュームにプロジェクト名付きの名前を割り当てます。
+// external / バインドマウントはoverrideで分離できないため解決しません。
+func (u *UnifiedOverrideGeneratorImpl) resolveVolumeConflicts(ctx context.Context, conflicts []types.VolumeConflictInfo) {
+	for i := range conflicts {
+		conflict := &conflicts[i]
+		if !conflict.IsResolvable() {
+			continue
+		}
+		if conflict.ProjectName == "" {
+			u.logger.Warn(ctx, "プロジェクト名が不明なためボリューム名を変更できません",
+				types.Field{Key: "volume", Value: conflict.VolumeName})
+			continue
+		}
+
+		resolvedName := fmt.Sprintf("%s-%s", conflict.ActualName, conflict.ProjectName)
+		conflict.Resolution = &types.VolumeResolutionInfo{
+			ResolvedName: resolvedName,
+			Reason:       fmt.Sprintf("ボリューム名 %s から %s への自動変更", conflict.ActualName, resolvedName),
+		}
+
+		u.logger.Info(ctx, "ボリューム衝突解決",
+			types.Field{Key: "volume", Value: conflict.VolumeName},
+			types.Field{Key: "from", Value: conflict.ActualName},
+			types.Field{Key: "to", Value: resolvedName})
+	}
+}
+
+// generateVolumeOverrides は解決済みのボリューム名をトップレベルの volumes に書き込みます。
+func (u *UnifiedOverrideGeneratorImpl) generateVolumeOverrides(conflicts []types.VolumeConflictInfo, override *types.OverrideConfig) {
+	for _, conflict := range conflicts {
+		if conflict.Resolution == nil {
+			continue
+		}
+		override.Volumes[conflict.VolumeName] = types.VolumeOverride{
+			Name: conflict.Resolution.ResolvedName,
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 // StructuredLogger は構造化ログの実装です。
 type StructuredLogger struct {
 	logger   *slog.Logger
+	output   io.Writer
 	fields   []types.Field
 	err      error
 	detailed bool
@@ -38,7 +39,8 @@ func (f *StructuredLoggerFactory) Create(config types.LogConfig) (Logger, error)
 
 // CreateWithName は名前付きロガーを作成します。
 func (f *StructuredLoggerFactory) CreateWithName(name string, config types.LogConfig) (Logger, error) {
-	var output io.Writer = os.Stdout
+	// 標準出力はoverrideの出力（-o -）に使うため、ログは標準エラー出力に書き込む
+	var output io.Writer = os.Stderr
 
 	// ファイル出力が指定されている場合
 	if config.File != "" {
@@ -68,6 +70,7 @@ func (f *StructuredLoggerFactory) CreateWithName(name string, config types.LogCo
 
 	return &StructuredLogger{
 		logger:   logger,
+		output:   output,
 		fields:   []types.Field{},
 		detailed: f.detailed,
 	}, nil
@@ -124,9 +127,11 @@ func (l *StructuredLogger) WithField(key string, value interface{}) Logger {
 	newFields[len(l.fields)] = types.Field{Key: key, Value: value}
 
 	return &StructuredLogger{
-		logger: l.logger,
-		fields: newFields,
-		err:    l.err,
+		logger:   l.logger,
+		output:   l.output,
+		fields:   newFields,
+		err:      l.err,
+		detailed: l.detailed,
 	}
 }
 
@@ -137,25 +142,29 @@ func (l *StructuredLogger) WithFields(fields ...types.Field) Logger {
 	copy(newFields[len(l.fields):], fields)
 
 	return &StructuredLogger{
-		logger: l.logger,
-		fields: newFields,
-		err:    l.err,
+		logger:   l.logger,
+		output:   l.output,
+		fields:   newFields,
+		err:      l.err,
+		detailed: l.detailed,
 	}
 }
 
 // WithError はエラーを追加した新しいロガーを返します。
 func (l *StructuredLogger) WithError(err error) Logger {
 	return &StructuredLogger{
-		logger: l.logger,
-		fields: l.fields,
-		err:    err,
+		logger:   l.logger,
+		output:   l.output,
+		fields:   l.fields,
+		err:      err,
+		detailed: l.detailed,
 	}
 }
 
 // log は実際のログ出力を行います。
 func (l *StructuredLogger) log(ctx context.Context, level slog.Level, message string, fields ...types.Field) {
 	if !l.detailed {
-		fmt.Println(message)
+		fmt.Fprintln(l.output, message)
 		return
 	}
 
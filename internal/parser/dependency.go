@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// DependencyCycleError は depends_on の循環を表すエラーです。
+type DependencyCycleError struct {
+	Cycles [][]string
+}
+
+// Error はエラーメッセージを返します。
+func (e *DependencyCycleError) Error() string {
+	cycles := make([]string, len(e.Cycles))
+	for i, cycle := range e.Cycles {
+		cycles[i] = strings.Join(cycle, " -> ")
+	}
+	return fmt.Sprintf("depends_on が循環しています: %s", strings.Join(cycles, ", "))
+}
+
+// BuildDependencyGraph は depends_on から依存関係グラフを構築し、起動順（依存先が先）を求めます。
+// 循環がある場合もグラフは返し、循環に含まれるサービスを除いた起動順と DependencyCycleError を返します。
+// 未定義のサービスへの依存はグラフに含めません。
+func BuildDependencyGraph(config *types.ComposeConfig) (*ComposeDependencyGraph, error) {
+	graph := &ComposeDependencyGraph{
+		Services: make(map[string][]string),
+	}
+	for name, service := range config.Services {
+		var dependencies []string
+		for _, dependency := range service.DependsOn {
+			if _, defined := config.Services[dependency]; defined && !containsString(dependencies, dependency) {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+		sort.Strings(dependencies)
+		graph.Services[name] = dependencies
+	}
+
+	// Kahn のアルゴリズム（同じ段階のサービスは名前順）
+	remaining := make(map[string]int)
+	for name, dependencies := range graph.Services {
+		remaining[name] = len(dependencies)
+	}
+	dependents := graph.reverse()
+
+	var ready []string
+	for name, count := range remaining {
+		if count == 0 {
+			ready = append(ready, name)
+		}
+	}
+	sort.Strings(ready)
+
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		graph.Order = append(graph.Order, name)
+
+		var next []string
+		for _, dependent := range dependents[name] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				next = append(next, dependent)
+			}
+		}
+		ready = append(ready, next...)
+		sort.Strings(ready)
+	}
+
+	if len(graph.Order) < len(graph.Services) {
+		return graph, &DependencyCycleError{Cycles: graph.Cycles()}
+	}
+	return graph, nil
+}
+
+// Cycles は循環している依存関係を返します（同じ循環は1回だけ、"a -> b -> a" の形）。
+func (g *ComposeDependencyGraph) Cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var cycles [][]string
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		for _, dependency := range g.Services[name] {
+			switch state[dependency] {
+			case unvisited:
+				visit(dependency)
+			case visiting:
+				start := 0
+				for i, service := range stack {
+					if service == dependency {
+						start = i
+						break
+					}
+				}
+				cycles = append(cycles, append(append([]string{}, stack[start:]...), dependency))
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+
+	for _, name := range sortedKeys(g.Services) {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	return cycles
+}
+
+// Dependencies は指定したサービスが（間接的に）依存するサービスを起動順で返します。
+func (g *ComposeDependencyGraph) Dependencies(service string) []string {
+	return g.inOrder(g.reachable(service, g.Services))
+}
+
+// Dependents は指定したサービスに（間接的に）依存するサービスを起動順で返します。
+// サービスのポートを変更した場合に影響を受けるサービスの一覧になります。
+func (g *ComposeDependencyGraph) Dependents(service string) []string {
+	return g.inOrder(g.reachable(service, g.reverse()))
+}
+
+// reverse は依存されている側から依存している側への辺を返します。
+func (g *ComposeDependencyGraph) reverse() map[string][]string {
+	reversed := make(map[string][]string)
+	for _, name := range sortedKeys(g.Services) {
+		for _, dependency := range g.Services[name] {
+			reversed[dependency] = append(reversed[dependency], name)
+		}
+	}
+	return reversed
+}
+
+// reachable は start から辿れるサービス（start 自身を除く）を返します。
+func (g *ComposeDependencyGraph) reachable(start string, edges map[string][]string) map[string]bool {
+	found := make(map[string]bool)
+	pending := append([]string{}, edges[start]...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if found[name] || name == start {
+			continue
+		}
+		found[name] = true
+		pending = append(pending, edges[name]...)
+	}
+	return found
+}
+
+// inOrder はサービスの集合を起動順（循環に含まれるものは名前順で末尾）に並べます。
+func (g *ComposeDependencyGraph) inOrder(services map[string]bool) []string {
+	var result []string
+	for _, name := range g.Order {
+		if services[name] {
+			result = append(result, name)
+			delete(services, name)
+		}
+	}
+	return append(result, sortedKeys(services)...)
+}
+
+// ServiceExtractorImpl はサービス情報抽出の実装です。
+type ServiceExtractorImpl struct {
+	logger logger.Logger
+}
+
+// NewServiceExtractorImpl は新しいServiceExtractorImplを作成します。
+func NewServiceExtractorImpl(logger logger.Logger) *ServiceExtractorImpl {
+	return &ServiceExtractorImpl{
+		logger: logger,
+	}
+}
+
+// ExtractServices は全てのサービスを名前順に返します。
+func (e *ServiceExtractorImpl) ExtractServices(ctx context.Context, config *types.ComposeConfig) ([]types.Service, error) {
+	services := make([]types.Service, 0, len(config.Services))
+	for _, name := range sortedServiceNames(config) {
+		service := config.Services[name]
+		service.Name = name
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+// ExtractService は指定した名前のサービスを返します。
+func (e *ServiceExtractorImpl) ExtractService(ctx context.Context, name string, config *types.ComposeConfig) (*types.Service, error) {
+	service, exists := config.Services[name]
+	if !exists {
+		return nil, &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: fmt.Sprintf("サービス %s が見つかりません", name),
+			Fields: map[string]interface{}{
+				"service": name,
+			},
+		}
+	}
+	service.Name = name
+	return &service, nil
+}
+
+// ExtractServiceDependencies は指定したサービスが（間接的に）依存するサービスを起動順で返します。
+// depends_on が循環している場合はエラーを返します。
+func (e *ServiceExtractorImpl) ExtractServiceDependencies(ctx context.Context, serviceName string, config *types.ComposeConfig) ([]string, error) {
+	if _, err := e.ExtractService(ctx, serviceName, config); err != nil {
+		return nil, err
+	}
+
+	graph, err := BuildDependencyGraph(config)
+	if err != nil {
+		return nil, err
+	}
+	return graph.Dependencies(serviceName), nil
+}
@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/internal/scanner"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// DockerComposeOptions は docker compose config に渡す引数を表します。
+type DockerComposeOptions struct {
+	ProjectName string
+	EnvFiles    []string
+	Profiles    []string
+}
+
+// DockerComposeParser は docker compose config で正規化した結果を解析する実装です。
+// anchors・extends・変数展開などの解釈を Compose 本体に任せるため、Compose仕様との差異がありません。
+// docker コマンドまたは compose プラグインが見つからない場合は YamlComposeParser で解析します。
+type DockerComposeParser struct {
+	logger   logger.Logger
+	options  DockerComposeOptions
+	fallback *YamlComposeParser
+}
+
+// NewDockerComposeParser は新しいDockerComposeParserを作成します。
+func NewDockerComposeParser(logger logger.Logger, options DockerComposeOptions) *DockerComposeParser {
+	return &DockerComposeParser{
+		logger:   logger,
+		options:  options,
+		fallback: NewYamlComposeParserWithEnvFiles(logger, options.EnvFiles),
+	}
+}
+
+// ParseComposeFiles は docker compose config --format json の出力を ComposeConfig に変換します。
+func (p *DockerComposeParser) ParseComposeFiles(ctx context.Context, filePaths []string) (*types.ComposeConfig, error) {
+	if len(filePaths) == 0 {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileNotFound,
+			Message: "Docker Composeファイルが指定されていません",
+		}
+	}
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		p.logger.Warn(ctx, "dockerコマンドが見つからないため、YAMLパーサーで解析します")
+		return p.fallback.ParseComposeFiles(ctx, filePaths)
+	}
+	if _, err := scanner.NewDockerComposeVersionDetector(p.logger).DetectVersion(ctx); err != nil {
+		p.logger.Warn(ctx, "docker compose が使用できないため、YAMLパーサーで解析します",
+			types.Field{Key: "error", Value: err.Error()})
+		return p.fallback.ParseComposeFiles(ctx, filePaths)
+	}
+
+	args := p.commandArgs(filePaths)
+	p.logger.Debug(ctx, "docker compose configで解析開始",
+		types.Field{Key: "args", Value: args})
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdin = os.Stdin // -f - の場合
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, &errors.AppError{
+			Code:    errors.ErrComposeInvalid,
+			Message: fmt.Sprintf("docker compose config の実行に失敗しました: %s", message),
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"files": filePaths,
+			},
+		}
+	}
+
+	doc, err := decodeNormalizedCompose(out, filePaths[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// 正規化済みのため include / extends は残っておらず、変数展開も不要
+	rc := &resolveContext{projectDir: filepath.Dir(filePaths[0])}
+	config, err := p.fallback.convertToComposeConfig(ctx, doc, rc)
+	if err != nil {
+		return nil, err
+	}
+	config.FilePaths = p.resolvedFilePaths(ctx, filePaths)
+	relativizeBindSources(config, rc.projectDir)
+
+	// docker compose config は name の無いボリュームに "<プロジェクト名>_<キー>" を補うため、
+	// 明示的に name: を指定した場合と区別できるよう既定の名前は取り除く
+	if projectName, ok := doc.raw["name"].(string); ok && projectName != "" {
+		for key, volume := range config.Volumes {
+			if volume.Name == projectName+"_"+key {
+				volume.Name = ""
+				config.Volumes[key] = volume
+			}
+		}
+	}
+
+	p.logger.Info(ctx, "docker compose configで解析完了",
+		types.Field{Key: "files", Value: filePaths},
+		types.Field{Key: "services_count", Value: len(config.Services)})
+
+	return config, nil
+}
+
+// resolvedFilePaths は include・extends で読み込むファイルを含む入力ファイルを返します。
+// docker compose config の出力には読み込んだファイルが残らないため、YAMLパーサーで解決して求めます。
+// 標準入力を含む場合や解決できない場合は -f で指定したファイルを返します。
+func (p *DockerComposeParser) resolvedFilePaths(ctx context.Context, filePaths []string) []string {
+	for _, filePath := range filePaths {
+		if filePath == StdinFilePath {
+			return append([]string{}, filePaths...)
+		}
+	}
+	resolved, err := NewYamlComposeParserWithEnvFiles(&logger.NopLogger{}, p.options.EnvFiles).ParseComposeFiles(ctx, filePaths)
+	if err != nil {
+		p.logger.Debug(ctx, "include・extends のファイルを解決できないため、指定したファイルのみを記録します",
+			types.Field{Key: "error", Value: err.Error()})
+		return append([]string{}, filePaths...)
+	}
+	return resolved.FilePaths
+}
+
+// relativizeBindSources は docker compose config が絶対パスに変換したバインドマウントのうち、
+// プロジェクトディレクトリ内のものを相対パス（./data など）に戻します。
+// 絶対パスのままではワークツリー間で共有されるバインドマウントと区別できないためです。
+func relativizeBindSources(config *types.ComposeConfig, projectDir string) {
+	absProjectDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return
+	}
+	for serviceName, service := range config.Services {
+		for i, mount := range service.Volumes {
+			if mount.Type != "bind" || !filepath.IsAbs(mount.Source) {
+				continue
+			}
+			rel, err := filepath.Rel(absProjectDir, mount.Source)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if rel == "." {
+				service.Volumes[i].Source = "."
+			} else {
+				service.Volumes[i].Source = "./" + filepath.ToSlash(rel)
+			}
+		}
+		config.Services[serviceName] = service
+	}
+}
+
+// commandArgs は docker compose config の引数を組み立てます（-f / -p / --env-file / --profile は up と同じ）。
+func (p *DockerComposeParser) commandArgs(filePaths []string) []string {
+	args := []string{"compose"}
+	for _, filePath := range filePaths {
+		args = append(args, "-f", filePath)
+	}
+	if p.options.ProjectName != "" {
+		args = append(args, "-p", p.options.ProjectName)
+	}
+	for _, envFile := range p.options.EnvFiles {
+		args = append(args, "--env-file", envFile)
+	}
+	for _, profile := range p.options.Profiles {
+		args = append(args, "--profile", profile)
+	}
+	return append(args, "config", "--format", "json")
+}
+
+// decodeNormalizedCompose は docker compose config のJSON出力を composeDocument に変換します。
+// 出力上の位置は元のファイルと対応しないため、位置情報はファイル名のみになります。
+func decodeNormalizedCompose(data []byte, filePath string) (*composeDocument, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "docker compose config の出力の解析に失敗しました",
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"file_path": filePath,
+			},
+		}
+	}
+	normalized, _ := normalizeJSONNumbers(raw).(map[string]interface{})
+
+	return &composeDocument{
+		filePath:  filePath,
+		raw:       normalized,
+		positions: map[string]types.SourceLocation{},
+	}, nil
+}
+
+// normalizeJSONNumbers は json.Number をYAMLのデコード結果と同じ int / float64 に変換します。
+func normalizeJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeJSONNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeJSONNumbers(item)
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// FormatDetectorImpl はDocker Composeファイルの形式（YAML / JSON）を判定する実装です。
+type FormatDetectorImpl struct {
+	logger logger.Logger
+}
+
+// NewFormatDetectorImpl は新しいFormatDetectorImplを作成します。
+func NewFormatDetectorImpl(logger logger.Logger) *FormatDetectorImpl {
+	return &FormatDetectorImpl{
+		logger: logger,
+	}
+}
+
+// DetectFormat はファイルの形式を判定します。
+// 拡張子（.json / .yml / .yaml）で判定できない場合は内容から判定します。
+func (d *FormatDetectorImpl) DetectFormat(ctx context.Context, filePath string) (ComposeFormat, error) {
+	if format, ok := FormatFromPath(filePath); ok {
+		return format, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: "ファイル読み込みに失敗しました: " + filePath,
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"file_path": filePath,
+			},
+		}
+	}
+	return d.DetectFormatFromBytes(ctx, data)
+}
+
+// DetectFormatFromBytes は内容から形式を判定します。
+// JSONとして妥当なオブジェクトであればJSON、それ以外はYAML（JSONを含む上位互換）とみなします。
+func (d *FormatDetectorImpl) DetectFormatFromBytes(ctx context.Context, data []byte) (ComposeFormat, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return "", &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "Docker Compose定義が空です",
+		}
+	}
+
+	if trimmed[0] == '{' && json.Valid(trimmed) {
+		return ComposeFormatJSON, nil
+	}
+	return ComposeFormatYAML, nil
+}
+
+// DetectVersion はComposeファイルに記載されたバージョンを返します。
+// version を省略したファイル（Compose Specification）の場合は空文字を返します。
+func (d *FormatDetectorImpl) DetectVersion(ctx context.Context, config *types.ComposeConfig) (string, error) {
+	if config == nil {
+		return "", &errors.AppError{
+			Code:    errors.ErrValidationFailed,
+			Message: "Docker Compose設定がありません",
+		}
+	}
+	return config.Version, nil
+}
+
+// FormatFromPath は拡張子から形式を判定します。判定できない場合は false を返します。
+func FormatFromPath(filePath string) (ComposeFormat, bool) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		return ComposeFormatJSON, true
+	case ".yml", ".yaml":
+		return ComposeFormatYAML, true
+	default:
+		return "", false
+	}
+}
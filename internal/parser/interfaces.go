@@ -16,6 +16,11 @@ type ComposeParser interface {
 	Validate(ctx context.Context, config *types.ComposeConfig) error
 }
 
+// ComposeFilesParser は複数のDocker Composeファイルを指定順にマージして解析するインターフェースです。
+type ComposeFilesParser interface {
+	ParseComposeFiles(ctx context.Context, filePaths []string) (*types.ComposeConfig, error)
+}
+
 // ServiceExtractor はサービス情報抽出を行うインターフェースです。
 type ServiceExtractor interface {
 	ExtractServices(ctx context.Context, config *types.ComposeConfig) ([]types.Service, error)
@@ -53,6 +58,16 @@ const (
 	ComposeFormatJSON ComposeFormat = "json"
 )
 
+// ParserBackend はDocker Composeファイル解析のバックエンドを表します。
+type ParserBackend string
+
+const (
+	// ParserBackendYAML はgopose自身のYAMLパーサーで解析します。
+	ParserBackendYAML ParserBackend = "yaml"
+	// ParserBackendDocker は docker compose config で正規化した結果を解析します。
+	ParserBackendDocker ParserBackend = "docker"
+)
+
 // ParseOptions は解析オプションを表します。
 type ParseOptions struct {
 	StrictMode       bool     `json:"strict_mode"`
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// envLookupFunc は変数名から値を引く関数です。
+type envLookupFunc func(name string) (string, bool)
+
+// interpolateValue はYAMLから読み込んだ値に含まれる文字列を再帰的に変数展開します。
+// キーは展開せず、値のみを対象とします（docker compose と同じ）。
+func interpolateValue(value interface{}, lookup envLookupFunc) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			interpolated, err := interpolateValue(item, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			result[key] = interpolated
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			interpolated, err := interpolateValue(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = interpolated
+		}
+		return result, nil
+	case string:
+		return interpolateString(v, lookup)
+	default:
+		return value, nil
+	}
+}
+
+// interpolateString は文字列中の $VAR / ${VAR} / ${VAR:-default} などを展開します。
+// 対応する構文: ${VAR:-d} ${VAR-d} ${VAR:?err} ${VAR?err} ${VAR:+alt} ${VAR+alt} と $$ のエスケープ。
+func interpolateString(s string, lookup envLookupFunc) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		next := s[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := findClosingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("変数展開の構文が無効です: %s", s)
+			}
+			value, err := expandBraced(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		case isVarNameStart(next):
+			j := i + 1
+			for j < len(s) && isVarNameChar(s[j]) {
+				j++
+			}
+			value, _ := lookup(s[i+1 : j])
+			b.WriteString(value)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandBraced は ${...} の中身を展開します。
+func expandBraced(expr string, lookup envLookupFunc) (string, error) {
+	nameEnd := 0
+	for nameEnd < len(expr) && isVarNameChar(expr[nameEnd]) {
+		nameEnd++
+	}
+	name := expr[:nameEnd]
+	if name == "" {
+		return "", fmt.Errorf("変数名が空です: ${%s}", expr)
+	}
+
+	rest := expr[nameEnd:]
+	value, set := lookup(name)
+	if rest == "" {
+		return value, nil
+	}
+
+	// 演算子の判定（":-" のようにコロン付きは空文字も未設定として扱う）
+	strict := strings.HasPrefix(rest, ":")
+	if strict {
+		rest = rest[1:]
+	}
+	if rest == "" {
+		return "", fmt.Errorf("変数展開の構文が無効です: ${%s}", expr)
+	}
+	op, arg := rest[0], rest[1:]
+	unset := !set || (strict && value == "")
+
+	switch op {
+	case '-':
+		if unset {
+			return interpolateString(arg, lookup)
+		}
+		return value, nil
+	case '?':
+		if unset {
+			message, err := interpolateString(arg, lookup)
+			if err != nil {
+				return "", err
+			}
+			return "", fmt.Errorf("必須の変数 %s が設定されていません: %s", name, message)
+		}
+		return value, nil
+	case '+':
+		if unset {
+			return "", nil
+		}
+		return interpolateString(arg, lookup)
+	default:
+		return "", fmt.Errorf("変数展開の構文が無効です: ${%s}", expr)
+	}
+}
+
+// findClosingBrace は入れ子を考慮して対応する '}' の位置を返します。
+func findClosingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isVarNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isVarNameChar(c byte) bool {
+	return isVarNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// mergeComposeConfigs は Compose のマージ規則に従って override を base に重ねた設定を返します。
+// 複数の -f ファイルは指定順にこの関数で畳み込まれます。
+func mergeComposeConfigs(base, override *types.ComposeConfig) *types.ComposeConfig {
+	merged := &types.ComposeConfig{
+		Version:   base.Version,
+		Services:  make(map[string]types.Service, len(base.Services)),
+		Networks:  make(map[string]types.Network, len(base.Networks)),
+		Volumes:   make(map[string]types.Volume, len(base.Volumes)),
+		FilePath:  base.FilePath,
+		FilePaths: appendFilePaths(append([]string{}, base.FilePaths...), override.FilePaths...),
+	}
+	if override.Version != "" {
+		merged.Version = override.Version
+	}
+	merged.Name = base.Name
+	if override.Name != "" {
+		merged.Name = override.Name
+	}
+
+	for name, service := range base.Services {
+		merged.Services[name] = service
+	}
+	for name, service := range override.Services {
+		if existing, exists := merged.Services[name]; exists {
+			merged.Services[name] = mergeService(existing, service)
+		} else {
+			merged.Services[name] = service
+		}
+	}
+
+	for name, network := range base.Networks {
+		merged.Networks[name] = network
+	}
+	for name, network := range override.Networks {
+		if existing, exists := merged.Networks[name]; exists {
+			merged.Networks[name] = mergeNetwork(existing, network)
+		} else {
+			merged.Networks[name] = network
+		}
+	}
+
+	for name, volume := range base.Volumes {
+		merged.Volumes[name] = volume
+	}
+	for name, volume := range override.Volumes {
+		if existing, exists := merged.Volumes[name]; exists {
+			merged.Volumes[name] = mergeVolume(existing, volume)
+		} else {
+			merged.Volumes[name] = volume
+		}
+	}
+
+	return merged
+}
+
+// mergeService はサービス定義をマージします。
+// スカラー値は上書き、ports/depends_on/profiles は重複を除いて追記、environment/networks はキー単位で上書きします。
+func mergeService(base, override types.Service) types.Service {
+	merged := base
+
+	if override.Image != "" {
+		merged.Image = override.Image
+	}
+	if override.NetworkMode != "" {
+		merged.NetworkMode = override.NetworkMode
+	}
+	if override.ContainerName != "" {
+		merged.ContainerName = override.ContainerName
+	}
+	merged.Links = mergeStringList(base.Links, override.Links)
+	merged.ExternalLinks = mergeStringList(base.ExternalLinks, override.ExternalLinks)
+	merged.VolumesFrom = mergeStringList(base.VolumesFrom, override.VolumesFrom)
+
+	// マウントはターゲット単位で上書き
+	merged.Volumes = append([]types.ServiceVolume{}, base.Volumes...)
+	for _, volume := range override.Volumes {
+		replaced := false
+		for i, existing := range merged.Volumes {
+			if existing.Target == volume.Target {
+				merged.Volumes[i] = volume
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.Volumes = append(merged.Volumes, volume)
+		}
+	}
+	if !override.Source.IsZero() {
+		merged.Source = override.Source
+	}
+
+	merged.Ports = append([]types.PortMapping{}, base.Ports...)
+	for _, port := range override.Ports {
+		if !containsPortMapping(merged.Ports, port) {
+			merged.Ports = append(merged.Ports, port)
+		}
+	}
+
+	merged.DependsOn = append([]string{}, base.DependsOn...)
+	for _, dep := range override.DependsOn {
+		if !containsString(merged.DependsOn, dep) {
+			merged.DependsOn = append(merged.DependsOn, dep)
+		}
+	}
+
+	if len(base.Dependencies) > 0 || len(override.Dependencies) > 0 {
+		merged.Dependencies = make(map[string]types.ServiceDependency)
+		for name, dependency := range base.Dependencies {
+			merged.Dependencies[name] = dependency
+		}
+		for name, dependency := range override.Dependencies {
+			merged.Dependencies[name] = dependency
+		}
+	}
+
+	merged.Expose = append([]int{}, base.Expose...)
+	for _, port := range override.Expose {
+		if !containsInt(merged.Expose, port) {
+			merged.Expose = append(merged.Expose, port)
+		}
+	}
+
+	merged.Profiles = append([]string{}, base.Profiles...)
+	for _, profile := range override.Profiles {
+		if !containsString(merged.Profiles, profile) {
+			merged.Profiles = append(merged.Profiles, profile)
+		}
+	}
+
+	if len(base.Environment) > 0 || len(override.Environment) > 0 {
+		merged.Environment = make(map[string]string, len(base.Environment)+len(override.Environment))
+		for key, value := range base.Environment {
+			merged.Environment[key] = value
+		}
+		for key, value := range override.Environment {
+			merged.Environment[key] = value
+		}
+	}
+
+	// env_file は追記（同じパスは後から指定された位置に移動し、後のファイルほど優先される）
+	if len(override.EnvFiles) > 0 {
+		merged.EnvFiles = nil
+		for _, envFile := range base.EnvFiles {
+			if !containsEnvFile(override.EnvFiles, envFile.Path) {
+				merged.EnvFiles = append(merged.EnvFiles, envFile)
+			}
+		}
+		merged.EnvFiles = append(merged.EnvFiles, override.EnvFiles...)
+	}
+
+	if len(base.Networks) > 0 || len(override.Networks) > 0 {
+		merged.Networks = make(map[string]types.ServiceNetwork, len(base.Networks)+len(override.Networks))
+		for name, network := range base.Networks {
+			merged.Networks[name] = network
+		}
+		for name, network := range override.Networks {
+			if network.IPv4Address == "" {
+				if existing, exists := merged.Networks[name]; exists {
+					network.IPv4Address = existing.IPv4Address
+				}
+			}
+			merged.Networks[name] = network
+		}
+	}
+
+	return merged
+}
+
+// containsEnvFile は env_file のリストに指定したパスが含まれているかを返します。
+func containsEnvFile(envFiles []types.EnvFile, path string) bool {
+	for _, envFile := range envFiles {
+		if envFile.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeNetwork はトップレベルのネットワーク定義をマージします。
+func mergeNetwork(base, override types.Network) types.Network {
+	merged := base
+
+	if override.Driver != "" {
+		merged.Driver = override.Driver
+	}
+	if override.IPAM.Driver != "" {
+		merged.IPAM.Driver = override.IPAM.Driver
+	}
+	if len(override.IPAM.Config) > 0 {
+		merged.IPAM.Config = override.IPAM.Config
+	}
+
+	merged.Labels = mergeStringMap(base.Labels, override.Labels)
+
+	return merged
+}
+
+// mergeVolume はトップレベルのボリューム定義をマージします。
+func mergeVolume(base, override types.Volume) types.Volume {
+	merged := base
+
+	if override.Driver != "" {
+		merged.Driver = override.Driver
+	}
+	merged.DriverOpts = mergeStringMap(base.DriverOpts, override.DriverOpts)
+	merged.Labels = mergeStringMap(base.Labels, override.Labels)
+	if override.Name != "" {
+		merged.Name = override.Name
+	}
+	if override.External {
+		merged.External = true
+	}
+
+	return merged
+}
+
+// mergeStringMap は2つの文字列マップをキー単位でマージします。
+func mergeStringMap(base, override map[string]string) map[string]string {
+	result := make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		result[key] = value
+	}
+	for key, value := range override {
+		result[key] = value
+	}
+	return result
+}
+
+// mergeStringList は重複を除いて文字列リストを追記します。
+func mergeStringList(base, override []string) []string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := append([]string{}, base...)
+	for _, value := range override {
+		if !containsString(merged, value) {
+			merged = append(merged, value)
+		}
+	}
+	return merged
+}
+
+// containsPortMapping は同一のポートマッピングが既に含まれているかを確認します。
+func containsPortMapping(ports []types.PortMapping, target types.PortMapping) bool {
+	for _, port := range ports {
+		if port.Host == target.Host &&
+			port.HostEnd == target.HostEnd &&
+			port.Container == target.Container &&
+			port.Protocol == target.Protocol &&
+			port.HostIP == target.HostIP {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt はスライスに数値が含まれているかを確認します。
+func containsInt(values []int, target int) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString はスライスに文字列が含まれているかを確認します。
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
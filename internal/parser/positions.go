@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// composeDocument は1つのComposeファイルの生データとYAML上の位置情報です。
+type composeDocument struct {
+	filePath  string
+	raw       map[string]interface{}
+	positions map[string]types.SourceLocation
+}
+
+// location はキーの経路（例: "services", "web", "ports", 0）に対応する位置を返します。
+// 位置が記録されていない場合はファイル名だけを持つ位置を返します。
+func (d *composeDocument) location(path ...interface{}) types.SourceLocation {
+	if loc, ok := d.positions[positionKey(path...)]; ok {
+		return loc
+	}
+	return types.SourceLocation{File: d.filePath}
+}
+
+// services は services セクションを返します。
+func (d *composeDocument) services() map[string]interface{} {
+	services, _ := d.raw["services"].(map[string]interface{})
+	return services
+}
+
+// positionKey は位置インデックスのキーを作ります。
+func positionKey(path ...interface{}) string {
+	parts := make([]string, len(path))
+	for i, part := range path {
+		parts[i] = fmt.Sprint(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// buildPositionIndex はYAMLノードを走査し、各キー・要素の位置を記録します。
+// マッピングはキーの位置、シーケンスは要素の位置を記録します。
+func buildPositionIndex(node *yaml.Node, filePath string) map[string]types.SourceLocation {
+	positions := make(map[string]types.SourceLocation)
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	indexNode(node, nil, filePath, positions)
+	return positions
+}
+
+func indexNode(node *yaml.Node, path []interface{}, filePath string, positions map[string]types.SourceLocation) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := append(append([]interface{}{}, path...), key.Value)
+			positions[positionKey(childPath...)] = nodeLocation(key, filePath)
+			indexNode(value, childPath, filePath, positions)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			childPath := append(append([]interface{}{}, path...), i)
+			positions[positionKey(childPath...)] = nodeLocation(item, filePath)
+			indexNode(item, childPath, filePath, positions)
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			indexNode(node.Alias, path, filePath, positions)
+		}
+	}
+}
+
+func nodeLocation(node *yaml.Node, filePath string) types.SourceLocation {
+	return types.SourceLocation{
+		File:   filePath,
+		Line:   node.Line,
+		Column: node.Column,
+	}
+}
+
+// withLocation はエラーに定義位置を付与します。
+// AppError の場合は Fields に位置を追加し、メッセージの先頭に file:line:col を付けます。
+func withLocation(err error, loc types.SourceLocation) error {
+	if err == nil || loc.IsZero() {
+		return err
+	}
+
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		if _, exists := appErr.Fields["location"]; exists {
+			return err
+		}
+		appErr.Message = fmt.Sprintf("%s: %s", loc, appErr.Message)
+		appErr.WithField("location", loc.String())
+		appErr.WithField("file_path", loc.File)
+		if loc.Line > 0 {
+			appErr.WithField("line", loc.Line)
+			appErr.WithField("column", loc.Column)
+		}
+		return err
+	}
+
+	return &errors.AppError{
+		Code:    errors.ErrParseFailed,
+		Message: fmt.Sprintf("%s: %s", loc, err.Error()),
+		Cause:   err,
+		Fields: map[string]interface{}{
+			"location":  loc.String(),
+			"file_path": loc.File,
+			"line":      loc.Line,
+			"column":    loc.Column,
+		},
+	}
+}
+
+// yamlErrorLinePattern はyaml.v3の構文エラーから行番号を取り出します。
+var yamlErrorLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorLocation はYAML構文エラーの位置を推定します。
+func yamlErrorLocation(err error, filePath string) types.SourceLocation {
+	loc := types.SourceLocation{File: filePath}
+	if matches := yamlErrorLinePattern.FindStringSubmatch(err.Error()); len(matches) == 2 {
+		loc.Line, _ = strconv.Atoi(matches[1])
+	}
+	return loc
+}
+
+// jsonErrorLocation はJSON構文エラーの位置（行・列）を求めます。
+// JSONとして構文エラーが見つからない場合は fallback を返します。
+func jsonErrorLocation(data []byte, filePath string, fallback types.SourceLocation) types.SourceLocation {
+	var syntaxErr *json.SyntaxError
+	if err := json.Unmarshal(data, new(interface{})); !stderrors.As(err, &syntaxErr) {
+		return fallback
+	}
+
+	// Offset はエラー直後までに読み込んだバイト数
+	end := int(syntaxErr.Offset) - 1
+	if end < 0 {
+		end = 0
+	} else if end > len(data) {
+		end = len(data)
+	}
+
+	loc := types.SourceLocation{File: filePath, Line: 1, Column: 1}
+	for _, c := range data[:end] {
+		if c == '\n' {
+			loc.Line++
+			loc.Column = 1
+		} else {
+			loc.Column++
+		}
+	}
+	return loc
+}
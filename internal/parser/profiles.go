@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// allProfiles は全てのプロファイルを有効にする指定です。
+const allProfiles = "*"
+
+// FilterActiveServices は有効なプロファイルに基づいて起動対象のサービスのみを含む設定を返します。
+// profiles を持たないサービスは常に有効です。明示的に指定されたサービスとその依存先も有効として扱います。
+func FilterActiveServices(config *types.ComposeConfig, profiles []string, explicitServices []string) (*types.ComposeConfig, []string) {
+	enabled := make(map[string]bool, len(profiles))
+	for _, profile := range profiles {
+		enabled[profile] = true
+	}
+
+	active := make(map[string]bool, len(config.Services))
+	for name, service := range config.Services {
+		if isServiceEnabled(service, enabled) {
+			active[name] = true
+		}
+	}
+
+	// 明示的に指定されたサービスは依存先を含めて有効化
+	visited := make(map[string]bool)
+	pending := append([]string{}, explicitServices...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+
+		service, exists := config.Services[name]
+		if !exists || visited[name] {
+			continue
+		}
+		visited[name] = true
+		active[name] = true
+		pending = append(pending, service.DependsOn...)
+	}
+
+	filtered := *config
+	filtered.Services = make(map[string]types.Service, len(active))
+	var inactive []string
+	for name, service := range config.Services {
+		if active[name] {
+			filtered.Services[name] = service
+		} else {
+			inactive = append(inactive, name)
+		}
+	}
+	sort.Strings(inactive)
+
+	return &filtered, inactive
+}
+
+// isServiceEnabled はサービスが有効なプロファイルに属しているかを判定します。
+func isServiceEnabled(service types.Service, enabled map[string]bool) bool {
+	if len(service.Profiles) == 0 || enabled[allProfiles] {
+		return true
+	}
+	for _, profile := range service.Profiles {
+		if enabled[profile] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,373 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/errors"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// resolveContext は include / extends の解決中に引き継ぐ状態です。
+type resolveContext struct {
+	// projectDir は相対パスと .env の基準となるプロジェクトディレクトリです。
+	projectDir string
+	// env は変数展開に使う値です（シェルの環境変数が env_file より優先）。
+	env map[string]string
+	// includeStack は現在解析中の include 連鎖（絶対パス）です。循環検出に使います。
+	includeStack []string
+	// partial は services を持たないファイルを許可します（2つ目以降にマージするファイルは networks・volumes だけでもよい）。
+	partial bool
+	// extendsFiles は extends の file で読み込んだファイルです。解決した入力として FilePaths に記録します。
+	extendsFiles []string
+}
+
+// newResolveContext は解決コンテキストを作成します。
+// envFiles が nil の場合はプロジェクトディレクトリの .env を読み込みます。
+func (p *YamlComposeParser) newResolveContext(ctx context.Context, projectDir string, envFiles []string) *resolveContext {
+	if envFiles == nil {
+		envFiles = []string{filepath.Join(projectDir, ".env")}
+	}
+
+	env := make(map[string]string)
+	for _, envFile := range envFiles {
+		values, err := readEnvFile(envFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				p.logger.Warn(ctx, "env ファイルの読み込みに失敗しました",
+					types.Field{Key: "file", Value: envFile},
+					types.Field{Key: "error", Value: err.Error()})
+			}
+			continue
+		}
+		for key, value := range values {
+			env[key] = value
+		}
+	}
+
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return &resolveContext{
+		projectDir: projectDir,
+		env:        env,
+	}
+}
+
+// lookupEnv は変数展開用に値を引きます。
+func (rc *resolveContext) lookupEnv(name string) (string, bool) {
+	value, ok := rc.env[name]
+	return value, ok
+}
+
+// enter は include 連鎖にファイルを積み、循環していればエラーを返します。
+func (rc *resolveContext) enter(filePath string) error {
+	absPath := absPath(filePath)
+	for _, included := range rc.includeStack {
+		if included == absPath {
+			chain := append(append([]string{}, rc.includeStack...), absPath)
+			return &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: fmt.Sprintf("include が循環しています: %s", strings.Join(chain, " -> ")),
+				Fields: map[string]interface{}{
+					"file_path": filePath,
+					"chain":     chain,
+				},
+			}
+		}
+	}
+	rc.includeStack = append(rc.includeStack, absPath)
+	return nil
+}
+
+// leave は include 連鎖から最後のファイルを取り除きます。
+func (rc *resolveContext) leave() {
+	if len(rc.includeStack) > 0 {
+		rc.includeStack = rc.includeStack[:len(rc.includeStack)-1]
+	}
+}
+
+// includeEntry はトップレベル include の1要素を表します。
+type includeEntry struct {
+	Paths            []string
+	ProjectDirectory string
+	EnvFiles         []string
+}
+
+// parseIncludeEntries は include セクションを解析します（短縮形・長形式の両方に対応）。
+func (p *YamlComposeParser) parseIncludeEntries(value interface{}) ([]includeEntry, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "includeセクションの形式が無効です",
+		}
+	}
+
+	var entries []includeEntry
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			entries = append(entries, includeEntry{Paths: []string{v}})
+		case map[string]interface{}:
+			entry := includeEntry{
+				Paths:    p.parseStringList(v["path"]),
+				EnvFiles: p.parseStringList(v["env_file"]),
+			}
+			if dir, ok := v["project_directory"].(string); ok {
+				entry.ProjectDirectory = dir
+			}
+			if len(entry.Paths) == 0 {
+				return nil, &errors.AppError{
+					Code:    errors.ErrParseFailed,
+					Message: "includeにpathが指定されていません",
+				}
+			}
+			entries = append(entries, entry)
+		default:
+			return nil, &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: "includeの要素の形式が無効です",
+				Fields: map[string]interface{}{
+					"include_type": fmt.Sprintf("%T", item),
+				},
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// resolveIncludes は include されたファイルを解析し、config に取り込みます。
+// include 側と同名のサービスが既に定義されている場合は docker compose と同様にエラーとします。
+func (p *YamlComposeParser) resolveIncludes(ctx context.Context, doc *composeDocument, config *types.ComposeConfig, rc *resolveContext) error {
+	filePath := doc.filePath
+	entries, err := p.parseIncludeEntries(doc.raw["include"])
+	if err != nil {
+		return withLocation(err, doc.location("include"))
+	}
+
+	baseDir := filepath.Dir(filePath)
+	for i, entry := range entries {
+		paths := make([]string, len(entry.Paths))
+		for j, path := range entry.Paths {
+			paths[j] = resolvePath(baseDir, path)
+		}
+
+		// プロジェクトディレクトリは指定がなければ最初のファイルのディレクトリ
+		projectDir := filepath.Dir(paths[0])
+		if entry.ProjectDirectory != "" {
+			projectDir = resolvePath(baseDir, entry.ProjectDirectory)
+		}
+
+		var envFiles []string
+		for _, envFile := range entry.EnvFiles {
+			envFiles = append(envFiles, resolvePath(baseDir, envFile))
+		}
+
+		child := p.newResolveContext(ctx, projectDir, envFiles)
+		child.includeStack = append([]string{}, rc.includeStack...)
+
+		var included *types.ComposeConfig
+		for j, path := range paths {
+			child.partial = j > 0
+			parsed, err := p.parseComposeFile(ctx, path, child)
+			if err != nil {
+				return withLocation(err, doc.location("include", i))
+			}
+			if included == nil {
+				included = parsed
+			} else {
+				included = mergeComposeConfigs(included, parsed)
+			}
+		}
+
+		for name, service := range included.Services {
+			if existing, exists := config.Services[name]; exists {
+				return &errors.AppError{
+					Code: errors.ErrParseFailed,
+					Message: fmt.Sprintf("サービス %s が %s と include された %s で重複しています",
+						name, existing.Source, service.Source),
+					Fields: map[string]interface{}{
+						"service":       name,
+						"file_path":     existing.Source.File,
+						"included_file": service.Source.File,
+					},
+				}
+			}
+			config.Services[name] = service
+		}
+		for name, network := range included.Networks {
+			if _, exists := config.Networks[name]; !exists {
+				config.Networks[name] = network
+			}
+		}
+		for name, volume := range included.Volumes {
+			if _, exists := config.Volumes[name]; !exists {
+				config.Volumes[name] = volume
+			}
+		}
+		config.FilePaths = appendFilePaths(config.FilePaths, included.FilePaths...)
+
+		p.logger.Debug(ctx, "includeを解決しました",
+			types.Field{Key: "file", Value: filePath},
+			types.Field{Key: "included", Value: paths})
+	}
+
+	return nil
+}
+
+// resolveExtends は extends で参照されたサービスを再帰的に解決し、継承元のサービス定義を返します。
+// stack は解決中のサービス（"ファイル#サービス"）の連鎖で、循環検出に使います。
+func (p *YamlComposeParser) resolveExtends(ctx context.Context, value interface{}, doc *composeDocument, rc *resolveContext, stack []string) (types.Service, error) {
+	filePath := doc.filePath
+	var baseName, baseFile string
+	switch v := value.(type) {
+	case string:
+		baseName = v
+	case map[string]interface{}:
+		baseName, _ = v["service"].(string)
+		baseFile, _ = v["file"].(string)
+	}
+	if baseName == "" {
+		return types.Service{}, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "extendsにserviceが指定されていません",
+			Fields: map[string]interface{}{
+				"file_path": filePath,
+			},
+		}
+	}
+
+	targetDoc := doc
+	if baseFile != "" {
+		var err error
+		targetDoc, err = p.loadRawCompose(ctx, resolvePath(filepath.Dir(filePath), baseFile), rc)
+		if err != nil {
+			return types.Service{}, err
+		}
+		rc.extendsFiles = append(rc.extendsFiles, targetDoc.filePath)
+	}
+	targetFile := targetDoc.filePath
+
+	key := extendsKey(targetFile, baseName)
+	for _, visited := range stack {
+		if visited == key {
+			chain := append(append([]string{}, stack...), key)
+			return types.Service{}, &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: fmt.Sprintf("extends が循環しています: %s", strings.Join(chain, " -> ")),
+				Fields: map[string]interface{}{
+					"file_path": filePath,
+					"chain":     chain,
+				},
+			}
+		}
+	}
+
+	baseMap, ok := targetDoc.services()[baseName].(map[string]interface{})
+	if !ok {
+		return types.Service{}, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: fmt.Sprintf("extendsで参照されたサービス %s が %s に見つかりません", baseName, targetFile),
+			Fields: map[string]interface{}{
+				"file_path": targetFile,
+				"service":   baseName,
+			},
+		}
+	}
+
+	base, err := p.convertToService(ctx, baseName, baseMap, targetDoc)
+	if err != nil {
+		return types.Service{}, fmt.Errorf("サービス %s の解析に失敗: %w", baseName, err)
+	}
+
+	if extends, exists := baseMap["extends"]; exists {
+		grandBase, err := p.resolveExtends(ctx, extends, targetDoc, rc, append(stack, key))
+		if err != nil {
+			return types.Service{}, withLocation(err, targetDoc.location("services", baseName, "extends"))
+		}
+		base = mergeService(grandBase, base)
+	}
+
+	return base, nil
+}
+
+// appendFilePaths は未記録のファイルだけを filePaths に追加します。
+func appendFilePaths(filePaths []string, additions ...string) []string {
+	for _, addition := range additions {
+		exists := false
+		for _, filePath := range filePaths {
+			if filePath == addition {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			filePaths = append(filePaths, addition)
+		}
+	}
+	return filePaths
+}
+
+// extendsKey は extends の循環検出に使うキーを作ります。
+func extendsKey(filePath, service string) string {
+	return absPath(filePath) + "#" + service
+}
+
+// resolvePath は baseDir を基準に相対パスを解決します。
+func resolvePath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// absPath は可能であれば絶対パスに変換します。
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// readEnvFile は KEY=VALUE 形式の env ファイルを読み込みます。
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 {
+			if (value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'') {
+				value = value[1 : len(value)-1]
+			}
+		}
+		values[key] = value
+	}
+
+	return values, scanner.Err()
+}
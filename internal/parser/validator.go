@@ -0,0 +1,391 @@
+package parser
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// ValidationRule は検証ルールの種類を表します。
+type ValidationRule string
+
+const (
+	ValidationRulePortRange         ValidationRule = "port_range"
+	ValidationRuleDuplicatePort     ValidationRule = "duplicate_port"
+	ValidationRuleInvalidSubnet     ValidationRule = "invalid_subnet"
+	ValidationRuleIPOutsideSubnet   ValidationRule = "ip_outside_subnet"
+	ValidationRuleUndefinedNetwork  ValidationRule = "undefined_network"
+	ValidationRuleUndefinedVolume   ValidationRule = "undefined_volume"
+	ValidationRuleUndefinedService  ValidationRule = "undefined_service"
+	ValidationRuleDependencyCycle   ValidationRule = "dependency_cycle"
+	ValidationRuleInvalidVolumeName ValidationRule = "invalid_volume_name"
+)
+
+// ValidationIssue は検証で見つかった問題を表します。
+type ValidationIssue struct {
+	Rule     ValidationRule       `json:"rule"`
+	Severity types.Severity       `json:"severity"`
+	Service  string               `json:"service,omitempty"`
+	Message  string               `json:"message"`
+	Source   types.SourceLocation `json:"source,omitempty"`
+}
+
+// String は "file:line:col: message" 形式の文字列を返します。
+func (i ValidationIssue) String() string {
+	if i.Source.IsZero() {
+		return i.Message
+	}
+	return fmt.Sprintf("%s: %s", i.Source, i.Message)
+}
+
+// ValidationError は検証で見つかった問題の一覧を表すエラーです。
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+// Error はエラーメッセージを返します。
+func (e *ValidationError) Error() string {
+	if len(e.Issues) == 1 {
+		return e.Issues[0].String()
+	}
+	return fmt.Sprintf("%d件の問題が見つかりました: %s", len(e.Issues), e.Issues[0])
+}
+
+// ValidationIssues はエラーに含まれる検証結果を取り出します。ValidationError でなければ nil を返します。
+func ValidationIssues(err error) []ValidationIssue {
+	var validationErr *ValidationError
+	if stderrors.As(err, &validationErr) {
+		return validationErr.Issues
+	}
+	return nil
+}
+
+// volumeNamePattern はDockerで使用できるボリューム名です。
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ComposeValidatorImpl はDocker Compose設定の妥当性検証の実装です。
+type ComposeValidatorImpl struct {
+	logger logger.Logger
+}
+
+// NewComposeValidatorImpl は新しいComposeValidatorImplを作成します。
+func NewComposeValidatorImpl(logger logger.Logger) *ComposeValidatorImpl {
+	return &ComposeValidatorImpl{
+		logger: logger,
+	}
+}
+
+// ValidateConfig は設定全体を検証します。
+// サービス単位の検証に加え、サービス間のポート重複、未定義のネットワーク・ボリューム・サービスの参照、
+// 固定IPとサブネットの整合性、depends_on の循環を検証します。
+func (v *ComposeValidatorImpl) ValidateConfig(ctx context.Context, config *types.ComposeConfig) error {
+	var issues []ValidationIssue
+
+	names := sortedServiceNames(config)
+	for _, name := range names {
+		service := config.Services[name]
+		service.Name = name
+		issues = append(issues, ValidationIssues(v.ValidateService(ctx, &service))...)
+	}
+	issues = append(issues, ValidationIssues(v.ValidateNetworks(ctx, config.Networks))...)
+	issues = append(issues, ValidationIssues(v.ValidateVolumes(ctx, config.Volumes))...)
+	issues = append(issues, v.duplicateHostBindings(config, names)...)
+	issues = append(issues, v.undefinedReferences(config, names)...)
+	issues = append(issues, v.dependencyCycles(config)...)
+
+	v.logger.Debug(ctx, "Docker Compose設定の検証完了",
+		types.Field{Key: "services_count", Value: len(config.Services)},
+		types.Field{Key: "issues", Value: len(issues)})
+
+	return newValidationError(issues)
+}
+
+// ValidateService はサービスのポート番号の範囲を検証します。
+func (v *ComposeValidatorImpl) ValidateService(ctx context.Context, service *types.Service) error {
+	var issues []ValidationIssue
+
+	for _, port := range service.Ports {
+		source := port.Source
+		if source.IsZero() {
+			source = service.Source
+		}
+		invalid := func(format string, args ...interface{}) {
+			issues = append(issues, ValidationIssue{
+				Rule:     ValidationRulePortRange,
+				Severity: types.SeverityError,
+				Service:  service.Name,
+				Message:  fmt.Sprintf("サービス %s: ", service.Name) + fmt.Sprintf(format, args...),
+				Source:   source,
+			})
+		}
+
+		if !isValidPort(port.Container) {
+			invalid("コンテナポート %d は 1〜65535 の範囲外です", port.Container)
+		}
+		if port.Host != 0 && !isValidPort(port.Host) {
+			invalid("ホストポート %d は 1〜65535 の範囲外です", port.Host)
+		}
+		if port.IsRange() {
+			if !isValidPort(port.HostEnd) {
+				invalid("ホストポートの範囲の終端 %d は 1〜65535 の範囲外です", port.HostEnd)
+			} else if port.HostEnd < port.Host {
+				invalid("ホストポートの範囲 %d-%d の終端が開始より小さくなっています", port.Host, port.HostEnd)
+			}
+		}
+	}
+
+	for _, port := range service.Expose {
+		if !isValidPort(port) {
+			issues = append(issues, ValidationIssue{
+				Rule:     ValidationRulePortRange,
+				Severity: types.SeverityError,
+				Service:  service.Name,
+				Message:  fmt.Sprintf("サービス %s: expose のポート %d は 1〜65535 の範囲外です", service.Name, port),
+				Source:   service.Source,
+			})
+		}
+	}
+
+	return newValidationError(issues)
+}
+
+// ValidateNetworks はネットワークのサブネットとゲートウェイを検証します。
+func (v *ComposeValidatorImpl) ValidateNetworks(ctx context.Context, networks map[string]types.Network) error {
+	var issues []ValidationIssue
+
+	for _, name := range sortedKeys(networks) {
+		network := networks[name]
+		for _, ipamConfig := range network.IPAM.Config {
+			if ipamConfig.Subnet == "" {
+				continue
+			}
+			_, subnet, err := net.ParseCIDR(ipamConfig.Subnet)
+			if err != nil {
+				issues = append(issues, ValidationIssue{
+					Rule:     ValidationRuleInvalidSubnet,
+					Severity: types.SeverityError,
+					Message:  fmt.Sprintf("ネットワーク %s のサブネット %s を解析できません", name, ipamConfig.Subnet),
+					Source:   network.Source,
+				})
+				continue
+			}
+
+			if ipamConfig.Gateway != "" {
+				gateway := net.ParseIP(ipamConfig.Gateway)
+				if gateway == nil || !subnet.Contains(gateway) {
+					issues = append(issues, ValidationIssue{
+						Rule:     ValidationRuleIPOutsideSubnet,
+						Severity: types.SeverityError,
+						Message: fmt.Sprintf("ネットワーク %s のゲートウェイ %s がサブネット %s に含まれていません",
+							name, ipamConfig.Gateway, ipamConfig.Subnet),
+						Source: network.Source,
+					})
+				}
+			}
+		}
+	}
+
+	return newValidationError(issues)
+}
+
+// ValidateVolumes は name: で指定されたボリューム名を検証します。
+func (v *ComposeValidatorImpl) ValidateVolumes(ctx context.Context, volumes map[string]types.Volume) error {
+	var issues []ValidationIssue
+
+	for _, key := range sortedKeys(volumes) {
+		volume := volumes[key]
+		if volume.Name != "" && !volumeNamePattern.MatchString(volume.Name) {
+			issues = append(issues, ValidationIssue{
+				Rule:     ValidationRuleInvalidVolumeName,
+				Severity: types.SeverityError,
+				Message:  fmt.Sprintf("ボリューム %s の名前 %s に使用できない文字が含まれています", key, volume.Name),
+				Source:   volume.Source,
+			})
+		}
+	}
+
+	return newValidationError(issues)
+}
+
+// duplicateHostBindings はサービス間で同じホストポートに公開している設定を検出します。
+// host_ip が異なる場合は重複としませんが、未指定（全アドレス）は全てのアドレスと重複します。
+func (v *ComposeValidatorImpl) duplicateHostBindings(config *types.ComposeConfig, names []string) []ValidationIssue {
+	type binding struct {
+		service string
+		hostIP  string
+	}
+
+	var issues []ValidationIssue
+	bindings := make(map[string][]binding) // "ポート/プロトコル" -> 公開済みのバインド
+	for _, name := range names {
+		for _, port := range config.Services[name].Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = "tcp"
+			}
+			for _, hostPort := range port.HostPorts() {
+				key := fmt.Sprintf("%d/%s", hostPort, protocol)
+				for _, existing := range bindings[key] {
+					if !hostIPsOverlap(existing.hostIP, port.HostIP) {
+						continue
+					}
+					source := port.Source
+					if source.IsZero() {
+						source = config.Services[name].Source
+					}
+					issues = append(issues, ValidationIssue{
+						Rule:     ValidationRuleDuplicatePort,
+						Severity: types.SeverityError,
+						Service:  name,
+						Message: fmt.Sprintf("サービス %s のホストポート %s はサービス %s と重複しています",
+							name, key, existing.service),
+						Source: source,
+					})
+					break
+				}
+				bindings[key] = append(bindings[key], binding{service: name, hostIP: port.HostIP})
+			}
+		}
+	}
+
+	return issues
+}
+
+// undefinedReferences は未定義のネットワーク・ボリューム・サービスへの参照と、固定IPの妥当性を検証します。
+func (v *ComposeValidatorImpl) undefinedReferences(config *types.ComposeConfig, names []string) []ValidationIssue {
+	var issues []ValidationIssue
+
+	for _, name := range names {
+		service := config.Services[name]
+		addIssue := func(rule ValidationRule, source types.SourceLocation, format string, args ...interface{}) {
+			if source.IsZero() {
+				source = service.Source
+			}
+			issues = append(issues, ValidationIssue{
+				Rule:     rule,
+				Severity: types.SeverityError,
+				Service:  name,
+				Message:  fmt.Sprintf("サービス %s: ", name) + fmt.Sprintf(format, args...),
+				Source:   source,
+			})
+		}
+
+		for _, networkName := range sortedKeys(service.Networks) {
+			network, defined := config.Networks[networkName]
+			if !defined {
+				if networkName != "default" {
+					addIssue(ValidationRuleUndefinedNetwork, types.SourceLocation{},
+						"未定義のネットワーク %s を参照しています", networkName)
+				}
+				continue
+			}
+
+			ipv4 := service.Networks[networkName].IPv4Address
+			if ipv4 == "" {
+				continue
+			}
+			ip := net.ParseIP(ipv4)
+			if ip == nil {
+				addIssue(ValidationRuleIPOutsideSubnet, types.SourceLocation{},
+					"ネットワーク %s の ipv4_address %s を解析できません", networkName, ipv4)
+				continue
+			}
+			if !subnetsContain(network, ip) {
+				addIssue(ValidationRuleIPOutsideSubnet, types.SourceLocation{},
+					"ipv4_address %s がネットワーク %s のサブネットに含まれていません", ipv4, networkName)
+			}
+		}
+
+		for _, mount := range service.Volumes {
+			if mount.Type != "volume" || mount.Source == "" {
+				continue
+			}
+			if _, defined := config.Volumes[mount.Source]; !defined {
+				addIssue(ValidationRuleUndefinedVolume, mount.Location,
+					"未定義のボリューム %s を参照しています", mount.Source)
+			}
+		}
+
+		for _, dependency := range service.DependsOn {
+			if _, defined := config.Services[dependency]; !defined {
+				addIssue(ValidationRuleUndefinedService, types.SourceLocation{},
+					"depends_on で未定義のサービス %s を参照しています", dependency)
+			}
+		}
+	}
+
+	return issues
+}
+
+// dependencyCycles は depends_on の循環を検出します。同じ循環は1回だけ報告します。
+func (v *ComposeValidatorImpl) dependencyCycles(config *types.ComposeConfig) []ValidationIssue {
+	graph, err := BuildDependencyGraph(config)
+	if err == nil {
+		return nil
+	}
+
+	var issues []ValidationIssue
+	for _, cycle := range graph.Cycles() {
+		issues = append(issues, ValidationIssue{
+			Rule:     ValidationRuleDependencyCycle,
+			Severity: types.SeverityError,
+			Service:  cycle[0],
+			Message:  fmt.Sprintf("depends_on が循環しています: %s", strings.Join(cycle, " -> ")),
+			Source:   config.Services[cycle[0]].Source,
+		})
+	}
+	return issues
+}
+
+// newValidationError は問題があれば ValidationError を返します。
+func newValidationError(issues []ValidationIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Issues: issues}
+}
+
+// subnetsContain はネットワークのいずれかのサブネットにIPが含まれるかを返します。
+// サブネットが定義されていないネットワークでは固定IPを指定できないため false になります。
+func subnetsContain(network types.Network, ip net.IP) bool {
+	for _, ipamConfig := range network.IPAM.Config {
+		if _, subnet, err := net.ParseCIDR(ipamConfig.Subnet); err == nil && subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostIPsOverlap は2つの host_ip が同じアドレスに公開されるかを返します。
+func hostIPsOverlap(a, b string) bool {
+	isWildcard := func(ip string) bool {
+		return ip == "" || ip == "0.0.0.0" || ip == "::"
+	}
+	return isWildcard(a) || isWildcard(b) || a == b
+}
+
+func isValidPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+// sortedServiceNames はサービス名を名前順に返します。
+func sortedServiceNames(config *types.ComposeConfig) []string {
+	return sortedKeys(config.Services)
+}
+
+// sortedKeys はマップのキーを名前順に返します。
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
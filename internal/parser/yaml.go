@@ -3,9 +3,11 @@ package parser
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,9 +17,14 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// StdinFilePath は標準入力を表すファイルパスです（-f -）。
+const StdinFilePath = "-"
+
 // YamlComposeParser はYAMLベースのDocker Compose解析実装です。
 type YamlComposeParser struct {
 	logger logger.Logger
+	// envFiles は変数展開に使う env ファイル（--env-file）です。nil の場合はプロジェクトディレクトリの .env を使います。
+	envFiles []string
 }
 
 // NewYamlComposeParser は新しいYamlComposeParserを作成します。
@@ -27,58 +34,208 @@ func NewYamlComposeParser(logger logger.Logger) *YamlComposeParser {
 	}
 }
 
+// NewYamlComposeParserWithEnvFiles は --env-file で指定したファイルを .env の代わりに変数展開に使うYamlComposeParserを作成します。
+// envFiles が空の場合は NewYamlComposeParser と同じです。
+func NewYamlComposeParserWithEnvFiles(logger logger.Logger, envFiles []string) *YamlComposeParser {
+	parser := NewYamlComposeParser(logger)
+	if len(envFiles) > 0 {
+		parser.envFiles = append([]string{}, envFiles...)
+	}
+	return parser
+}
+
 // ParseComposeFile はDocker Composeファイルを解析します。
-func (p *YamlComposeParser) ParseComposeFile(ctx context.Context, filepath string) (*types.ComposeConfig, error) {
-	p.logger.Debug(ctx, "Docker Composeファイル解析開始", types.Field{Key: "file", Value: filepath})
+func (p *YamlComposeParser) ParseComposeFile(ctx context.Context, filePath string) (*types.ComposeConfig, error) {
+	rc := p.newResolveContext(ctx, filepath.Dir(filePath), p.envFiles)
+	return p.parseComposeFile(ctx, filePath, rc)
+}
+
+// parseComposeFile は解決コンテキストを引き継いでDocker Composeファイルを解析します。
+func (p *YamlComposeParser) parseComposeFile(ctx context.Context, filePath string, rc *resolveContext) (*types.ComposeConfig, error) {
+	p.logger.Debug(ctx, "Docker Composeファイル解析開始", types.Field{Key: "file", Value: filePath})
+
+	// include の循環検出
+	if err := rc.enter(filePath); err != nil {
+		return nil, err
+	}
+	defer rc.leave()
+
+	doc, err := p.loadRawCompose(ctx, filePath, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	// ComposeConfigに変換
+	config, err := p.convertToComposeConfig(ctx, doc, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.Info(ctx, "Docker Composeファイル解析完了",
+		types.Field{Key: "file", Value: filePath},
+		types.Field{Key: "services_count", Value: len(config.Services)})
+
+	return config, nil
+}
+
+// ParseFromReader はReaderから読み込んだDocker Compose定義を解析します。
+// 相対パスと .env はカレントディレクトリを基準に解決します（docker compose -f - と同じ）。
+func (p *YamlComposeParser) ParseFromReader(ctx context.Context, reader io.Reader) (*types.ComposeConfig, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileReadFailed,
+			Message: "Docker Compose定義の読み込みに失敗しました",
+			Cause:   err,
+		}
+	}
+	return p.ParseFromBytes(ctx, data)
+}
+
+// ParseFromBytes はバイト列のDocker Compose定義を解析します。
+func (p *YamlComposeParser) ParseFromBytes(ctx context.Context, data []byte) (*types.ComposeConfig, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリの取得に失敗: %w", err)
+	}
+
+	rc := p.newResolveContext(ctx, wd, p.envFiles)
+	doc, err := p.decodeRawCompose(data, StdinFilePath, rc)
+	if err != nil {
+		return nil, err
+	}
+	return p.convertToComposeConfig(ctx, doc, rc)
+}
+
+// loadRawCompose はファイルを読み込み、変数展開済みの生のYAMLデータと位置情報を返します。
+// filePath が "-" の場合は標準入力から読み込みます。
+func (p *YamlComposeParser) loadRawCompose(ctx context.Context, filePath string, rc *resolveContext) (*composeDocument, error) {
+	if filePath == StdinFilePath {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, &errors.AppError{
+				Code:    errors.ErrFileReadFailed,
+				Message: "標準入力の読み込みに失敗しました",
+				Cause:   err,
+			}
+		}
+		return p.decodeRawCompose(data, filePath, rc)
+	}
 
 	// ファイルの存在確認
-	if _, err := os.Stat(filepath); os.IsNotExist(err) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return nil, &errors.AppError{
 			Code:    errors.ErrFileNotFound,
-			Message: fmt.Sprintf("Docker Composeファイルが見つかりません: %s", filepath),
+			Message: fmt.Sprintf("Docker Composeファイルが見つかりません: %s", filePath),
 			Fields: map[string]interface{}{
-				"file_path": filepath,
+				"file_path": filePath,
 			},
 		}
 	}
 
 	// ファイル読み込み
-	data, err := os.ReadFile(filepath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, &errors.AppError{
 			Code:    errors.ErrFileReadFailed,
-			Message: fmt.Sprintf("ファイル読み込みに失敗しました: %s", filepath),
+			Message: fmt.Sprintf("ファイル読み込みに失敗しました: %s", filePath),
 			Cause:   err,
 			Fields: map[string]interface{}{
-				"file_path": filepath,
+				"file_path": filePath,
 			},
 		}
 	}
 
-	// YAML解析
+	return p.decodeRawCompose(data, filePath, rc)
+}
+
+// decodeRawCompose はYAML（JSONを含む）をデコードし、変数展開済みの生データと位置情報を返します。
+func (p *YamlComposeParser) decodeRawCompose(data []byte, filePath string, rc *resolveContext) (*composeDocument, error) {
+	// JSONはYAMLの部分集合のため同じ経路で解析する（形式はエラー表示にのみ使う）
+	format, isKnown := FormatFromPath(filePath)
+	if !isKnown {
+		format, _ = NewFormatDetectorImpl(p.logger).DetectFormatFromBytes(context.Background(), data)
+	}
+
+	// YAML解析（位置情報を残すため yaml.Node 経由でデコード）
+	var root yaml.Node
 	var rawCompose map[string]interface{}
-	if err := yaml.Unmarshal(data, &rawCompose); err != nil {
+	err := yaml.Unmarshal(data, &root)
+	if err == nil && len(root.Content) > 0 {
+		err = root.Decode(&rawCompose)
+	}
+	if err != nil {
+		message, loc := "YAMLの解析に失敗しました", yamlErrorLocation(err, filePath)
+		if format == ComposeFormatJSON {
+			message, loc = "JSONの解析に失敗しました", jsonErrorLocation(data, filePath, loc)
+		}
+		return nil, withLocation(&errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: message,
+			Cause:   err,
+			Fields: map[string]interface{}{
+				"file_path": filePath,
+				"format":    format,
+			},
+		}, loc)
+	}
+
+	// 変数展開
+	interpolated, err := interpolateValue(rawCompose, rc.lookupEnv)
+	if err != nil {
 		return nil, &errors.AppError{
 			Code:    errors.ErrParseFailed,
-			Message: "YAMLの解析に失敗しました",
+			Message: "変数展開に失敗しました",
 			Cause:   err,
 			Fields: map[string]interface{}{
-				"file_path": filepath,
+				"file_path": filePath,
 			},
 		}
 	}
+	rawCompose, _ = interpolated.(map[string]interface{})
 
-	// ComposeConfigに変換
-	config, err := p.convertToComposeConfig(ctx, rawCompose, filepath)
-	if err != nil {
-		return nil, err
+	return &composeDocument{
+		filePath:  filePath,
+		raw:       rawCompose,
+		positions: buildPositionIndex(&root, filePath),
+	}, nil
+}
+
+// ParseComposeFiles は複数のDocker Composeファイルを指定順に解析し、Composeのマージ規則で統合します。
+// 後に指定されたファイルほど優先され、docker compose -f を複数指定した場合と同じ結果になります。
+func (p *YamlComposeParser) ParseComposeFiles(ctx context.Context, filePaths []string) (*types.ComposeConfig, error) {
+	if len(filePaths) == 0 {
+		return nil, &errors.AppError{
+			Code:    errors.ErrFileNotFound,
+			Message: "Docker Composeファイルが指定されていません",
+		}
 	}
 
-	p.logger.Info(ctx, "Docker Composeファイル解析完了",
-		types.Field{Key: "file", Value: filepath},
-		types.Field{Key: "services_count", Value: len(config.Services)})
+	// プロジェクトディレクトリは最初のファイルのディレクトリ（docker compose と同じ）
+	rc := p.newResolveContext(ctx, filepath.Dir(filePaths[0]), p.envFiles)
 
-	return config, nil
+	var merged *types.ComposeConfig
+	for i, filePath := range filePaths {
+		rc.partial = i > 0
+		config, err := p.parseComposeFile(ctx, filePath, rc)
+		if err != nil {
+			return nil, err
+		}
+
+		if merged == nil {
+			merged = config
+			continue
+		}
+		merged = mergeComposeConfigs(merged, config)
+	}
+
+	if len(filePaths) > 1 {
+		p.logger.Info(ctx, "Docker Composeファイルのマージ完了",
+			types.Field{Key: "files", Value: filePaths},
+			types.Field{Key: "services_count", Value: len(merged.Services)})
+	}
+
+	return merged, nil
 }
 
 // ParseServicePorts はサービスのポート設定を解析します。
@@ -141,13 +298,19 @@ func (p *YamlComposeParser) ValidateComposeVersion(ctx context.Context, version
 }
 
 // convertToComposeConfig は生のYAMLデータをComposeConfigに変換します。
-func (p *YamlComposeParser) convertToComposeConfig(ctx context.Context, raw map[string]interface{}, filepath string) (*types.ComposeConfig, error) {
+func (p *YamlComposeParser) convertToComposeConfig(ctx context.Context, doc *composeDocument, rc *resolveContext) (*types.ComposeConfig, error) {
+	raw, filePath := doc.raw, doc.filePath
 	config := &types.ComposeConfig{
-		Version:  p.extractVersion(raw),
-		Services: make(map[string]types.Service),
-		Networks: make(map[string]types.Network),
-		Volumes:  make(map[string]types.Volume),
-		FilePath: filepath,
+		Version:   p.extractVersion(raw),
+		Services:  make(map[string]types.Service),
+		Networks:  make(map[string]types.Network),
+		Volumes:   make(map[string]types.Volume),
+		FilePath:  filePath,
+		FilePaths: []string{filePath},
+	}
+
+	if name, ok := raw["name"].(string); ok {
+		config.Name = name
 	}
 
 	// バージョン検証
@@ -156,38 +319,61 @@ func (p *YamlComposeParser) convertToComposeConfig(ctx context.Context, raw map[
 	}
 
 	// サービス解析
+	// include だけで構成されるファイルと、2つ目以降にマージするファイルは services を持たなくてもよい
+	_, hasInclude := raw["include"]
 	servicesInterface, exists := raw["services"]
 	if !exists {
-		return nil, &errors.AppError{
-			Code:    errors.ErrParseFailed,
-			Message: "servicesセクションが見つかりません",
+		if !hasInclude && !rc.partial {
+			return nil, &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: "servicesセクションが見つかりません",
+			}
 		}
+		servicesInterface = map[string]interface{}{}
 	}
 
 	services, ok := servicesInterface.(map[string]interface{})
 	if !ok {
-		return nil, &errors.AppError{
+		return nil, withLocation(&errors.AppError{
 			Code:    errors.ErrParseFailed,
 			Message: "servicesセクションの形式が無効です",
-		}
+		}, doc.location("services"))
 	}
 
+	extendsStart := len(rc.extendsFiles)
 	for serviceName, serviceInterface := range services {
 		serviceMap, ok := serviceInterface.(map[string]interface{})
 		if !ok {
 			p.logger.Warn(ctx, "サービス設定の形式が無効です",
-				types.Field{Key: "service", Value: serviceName})
+				types.Field{Key: "service", Value: serviceName},
+				types.Field{Key: "location", Value: doc.location("services", serviceName).String()})
 			continue
 		}
 
-		service, err := p.convertToService(ctx, serviceName, serviceMap)
+		service, err := p.convertToService(ctx, serviceName, serviceMap, doc)
 		if err != nil {
 			return nil, fmt.Errorf("サービス %s の解析に失敗: %w", serviceName, err)
 		}
 
+		// extends の解決
+		if extends, exists := serviceMap["extends"]; exists {
+			stack := []string{extendsKey(filePath, serviceName)}
+			base, err := p.resolveExtends(ctx, extends, doc, rc, stack)
+			if err != nil {
+				return nil, withLocation(err, doc.location("services", serviceName, "extends"))
+			}
+			service = mergeService(base, service)
+			service.Name = serviceName
+		}
+
 		config.Services[serviceName] = service
 	}
 
+	// extends で読み込んだファイルも入力として記録（サービスの走査順に依存しないよう名前順）
+	extendsFiles := append([]string{}, rc.extendsFiles[extendsStart:]...)
+	sort.Strings(extendsFiles)
+	config.FilePaths = appendFilePaths(config.FilePaths, extendsFiles...)
+
 	// ネットワーク解析
 	if networksInterface, exists := raw["networks"]; exists {
 		networks, ok := networksInterface.(map[string]interface{})
@@ -196,14 +382,17 @@ func (p *YamlComposeParser) convertToComposeConfig(ctx context.Context, raw map[
 				networkMap, ok := networkInterface.(map[string]interface{})
 				if !ok {
 					p.logger.Warn(ctx, "ネットワーク設定の形式が無効です",
-						types.Field{Key: "network", Value: networkName})
+						types.Field{Key: "network", Value: networkName},
+						types.Field{Key: "location", Value: doc.location("networks", networkName).String()})
 					continue
 				}
 
 				network, err := p.convertToNetwork(ctx, networkName, networkMap)
 				if err != nil {
-					return nil, fmt.Errorf("ネットワーク %s の解析に失敗: %w", networkName, err)
+					return nil, fmt.Errorf("ネットワーク %s の解析に失敗: %w", networkName,
+						withLocation(err, doc.location("networks", networkName)))
 				}
+				network.Source = doc.location("networks", networkName)
 
 				config.Networks[networkName] = network
 			}
@@ -215,6 +404,9 @@ func (p *YamlComposeParser) convertToComposeConfig(ctx context.Context, raw map[
 		volumes, ok := volumesInterface.(map[string]interface{})
 		if ok {
 			for volumeName, volumeInterface := range volumes {
+				if volumeInterface == nil {
+					volumeInterface = map[string]interface{}{} // "pgdata:" のような値なしの宣言
+				}
 				volumeMap, ok := volumeInterface.(map[string]interface{})
 				if !ok {
 					p.logger.Warn(ctx, "ボリューム設定の形式が無効です",
@@ -226,19 +418,28 @@ func (p *YamlComposeParser) convertToComposeConfig(ctx context.Context, raw map[
 				if err != nil {
 					return nil, fmt.Errorf("ボリューム %s の解析に失敗: %w", volumeName, err)
 				}
+				volume.Source = doc.location("volumes", volumeName)
 
 				config.Volumes[volumeName] = volume
 			}
 		}
 	}
 
+	// include の解決
+	if hasInclude {
+		if err := p.resolveIncludes(ctx, doc, config, rc); err != nil {
+			return nil, err
+		}
+	}
+
 	return config, nil
 }
 
 // convertToService はサービス設定を変換します。
-func (p *YamlComposeParser) convertToService(ctx context.Context, name string, serviceMap map[string]interface{}) (types.Service, error) {
+func (p *YamlComposeParser) convertToService(ctx context.Context, name string, serviceMap map[string]interface{}, doc *composeDocument) (types.Service, error) {
 	service := types.Service{
-		Name: name,
+		Name:   name,
+		Source: doc.location("services", name),
 	}
 
 	// イメージ情報
@@ -248,21 +449,51 @@ func (p *YamlComposeParser) convertToService(ctx context.Context, name string, s
 		}
 	}
 
-	// ポートマッピング解析
-	portMappings, err := p.ParseServicePorts(ctx, serviceMap)
-	if err != nil {
-		return service, err
+	// ポートマッピング解析（各ポートに定義位置を記録）
+	if portsInterface, exists := serviceMap["ports"]; exists {
+		ports, ok := portsInterface.([]interface{})
+		if !ok {
+			return service, withLocation(&errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: "ポート設定の形式が無効です",
+				Fields: map[string]interface{}{
+					"ports_type": fmt.Sprintf("%T", portsInterface),
+				},
+			}, doc.location("services", name, "ports"))
+		}
+		for i, portInterface := range ports {
+			loc := doc.location("services", name, "ports", i)
+			mapping, err := p.parsePortMapping(ctx, portInterface)
+			if err != nil {
+				return service, withLocation(err, loc)
+			}
+			if mapping != nil {
+				mapping.Source = loc
+				service.Ports = append(service.Ports, *mapping)
+			}
+		}
 	}
-	service.Ports = portMappings
 
 	// 環境変数
 	if env, exists := serviceMap["environment"]; exists {
 		service.Environment = p.parseEnvironment(env)
 	}
+	if envFile, exists := serviceMap["env_file"]; exists {
+		envFiles, err := p.parseEnvFiles(ctx, envFile, filepath.Dir(doc.filePath))
+		if err != nil {
+			return service, withLocation(err, doc.location("services", name, "env_file"))
+		}
+		service.EnvFiles = envFiles
+	}
 
 	// 依存関係
 	if depends, exists := serviceMap["depends_on"]; exists {
-		service.DependsOn = p.parseDependsOn(depends)
+		dependencies, err := p.parseDependsOn(depends)
+		if err != nil {
+			return types.Service{}, withLocation(err, doc.location("services", name, "depends_on"))
+		}
+		service.Dependencies = dependencies
+		service.DependsOn = sortedKeys(dependencies)
 	}
 
 	// ネットワーク設定
@@ -270,6 +501,54 @@ func (p *YamlComposeParser) convertToService(ctx context.Context, name string, s
 		service.Networks = p.parseNetworks(networks)
 	}
 
+	// プロファイル
+	if profiles, exists := serviceMap["profiles"]; exists {
+		service.Profiles = p.parseStringList(profiles)
+	}
+
+	// ネットワークモード
+	if networkMode, ok := serviceMap["network_mode"].(string); ok {
+		service.NetworkMode = networkMode
+	}
+
+	// コンテナ名とそれを参照しうる設定
+	if containerName, ok := serviceMap["container_name"].(string); ok {
+		service.ContainerName = containerName
+	}
+	if links, exists := serviceMap["links"]; exists {
+		service.Links = p.parseStringList(links)
+	}
+	if externalLinks, exists := serviceMap["external_links"]; exists {
+		service.ExternalLinks = p.parseStringList(externalLinks)
+	}
+	if volumesFrom, exists := serviceMap["volumes_from"]; exists {
+		service.VolumesFrom = p.parseStringList(volumesFrom)
+	}
+
+	// ボリュームマウント
+	if volumes, ok := serviceMap["volumes"].([]interface{}); ok {
+		for i, volumeInterface := range volumes {
+			loc := doc.location("services", name, "volumes", i)
+			volume, err := p.parseServiceVolume(volumeInterface)
+			if err != nil {
+				return service, withLocation(err, loc)
+			}
+			if volume != nil {
+				volume.Location = loc
+				service.Volumes = append(service.Volumes, *volume)
+			}
+		}
+	}
+
+	// expose（ホストには公開されないが、network_mode: host ではホストのポートになる）
+	if expose, exists := serviceMap["expose"]; exists {
+		exposed, err := p.parseExpose(expose)
+		if err != nil {
+			return service, withLocation(err, doc.location("services", name, "expose"))
+		}
+		service.Expose = exposed
+	}
+
 	return service, nil
 }
 
@@ -295,7 +574,7 @@ func (p *YamlComposeParser) parsePortMapping(ctx context.Context, portInterface
 
 // parsePortString は文字列形式のポートマッピングを解析します。
 func (p *YamlComposeParser) parsePortString(ctx context.Context, portStr string) (*types.PortMapping, error) {
-	// 例: "8080:80", "8080:80/tcp", "127.0.0.1:8080:80"
+	// 例: "8080:80", "8080:80/tcp", "127.0.0.1:8080:80", "[::1]:8080:80"
 
 	protocol := "tcp"
 	portPart := portStr
@@ -310,7 +589,7 @@ func (p *YamlComposeParser) parsePortString(ctx context.Context, portStr string)
 	}
 
 	// ポート部分を解析
-	re := regexp.MustCompile(`^(?:([^:]+):)?(\d+):(\d+)$|^(\d+)$`)
+	re := regexp.MustCompile(`^(?:(\[[0-9A-Fa-f:.]+\]|[^:\[\]]+):)?(\d+):(\d+)$|^(\d+)$`)
 	matches := re.FindStringSubmatch(portPart)
 
 	if len(matches) == 0 {
@@ -363,24 +642,25 @@ func (p *YamlComposeParser) parsePortString(ctx context.Context, portStr string)
 
 	// IPアドレスが指定されている場合
 	if matches[1] != "" {
-		mapping.HostIP = matches[1]
+		mapping.HostIP = strings.TrimSuffix(strings.TrimPrefix(matches[1], "["), "]")
 	}
 
 	return mapping, nil
 }
 
-// parsePortObject はオブジェクト形式のポートマッピングを解析します。
+// parsePortObject はオブジェクト形式（長形式）のポートマッピングを解析します。
 func (p *YamlComposeParser) parsePortObject(ctx context.Context, portObj map[string]interface{}) (*types.PortMapping, error) {
 	mapping := &types.PortMapping{
-		Protocol: "tcp", // デフォルト
+		Protocol:   "tcp", // デフォルト
+		LongSyntax: true,
 	}
 
-	// published (ホストポート)
+	// published (ホストポート、"8000-8010" のような範囲指定も可)
 	if published, exists := portObj["published"]; exists {
 		if port, ok := published.(int); ok {
 			mapping.Host = port
 		} else if portStr, ok := published.(string); ok {
-			port, err := strconv.Atoi(portStr)
+			start, end, err := parsePortRange(portStr)
 			if err != nil {
 				return nil, &errors.AppError{
 					Code:    errors.ErrParseFailed,
@@ -388,7 +668,10 @@ func (p *YamlComposeParser) parsePortObject(ctx context.Context, portObj map[str
 					Cause:   err,
 				}
 			}
-			mapping.Host = port
+			mapping.Host = start
+			if end != start {
+				mapping.HostEnd = end
+			}
 		}
 	}
 
@@ -408,6 +691,12 @@ func (p *YamlComposeParser) parsePortObject(ctx context.Context, portObj map[str
 			mapping.Container = port
 		}
 	}
+	if mapping.Container == 0 {
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "ポートの長形式にtargetが指定されていません",
+		}
+	}
 
 	// protocol
 	if protocol, exists := portObj["protocol"]; exists {
@@ -423,9 +712,142 @@ func (p *YamlComposeParser) parsePortObject(ctx context.Context, portObj map[str
 		}
 	}
 
+	// mode (ingress / host)
+	if mode, exists := portObj["mode"]; exists {
+		modeStr, _ := mode.(string)
+		if modeStr != "ingress" && modeStr != "host" {
+			return nil, &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: fmt.Sprintf("無効なポートmode: %v (ingress または host を指定してください)", mode),
+			}
+		}
+		mapping.Mode = modeStr
+	}
+
+	// name / app_protocol
+	if name, ok := portObj["name"].(string); ok {
+		mapping.Name = name
+	}
+	if appProtocol, ok := portObj["app_protocol"].(string); ok {
+		mapping.AppProtocol = appProtocol
+	}
+
 	return mapping, nil
 }
 
+// parseServiceVolume はサービスのボリュームマウントを解析します（短縮形・長形式の両方に対応）。
+func (p *YamlComposeParser) parseServiceVolume(value interface{}) (*types.ServiceVolume, error) {
+	switch v := value.(type) {
+	case string:
+		// 例: "pgdata:/var/lib/postgresql/data", "./src:/app:ro", "/data"
+		parts := strings.Split(v, ":")
+		volume := &types.ServiceVolume{}
+		switch {
+		case len(parts) == 1:
+			volume.Target = parts[0]
+		case len(parts) >= 2:
+			volume.Source = parts[0]
+			volume.Target = parts[1]
+			if len(parts) >= 3 {
+				for _, mode := range strings.Split(parts[2], ",") {
+					if mode == "ro" {
+						volume.ReadOnly = true
+					}
+				}
+			}
+		}
+		volume.Type = volumeTypeFromSource(volume.Source)
+		return volume, nil
+	case map[string]interface{}:
+		volume := &types.ServiceVolume{}
+		volume.Type, _ = v["type"].(string)
+		volume.Source, _ = v["source"].(string)
+		volume.Target, _ = v["target"].(string)
+		volume.ReadOnly, _ = v["read_only"].(bool)
+		if volume.Type == "" {
+			volume.Type = volumeTypeFromSource(volume.Source)
+		}
+		if volume.Target == "" {
+			return nil, &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: "ボリュームの長形式にtargetが指定されていません",
+			}
+		}
+		return volume, nil
+	default:
+		return nil, nil
+	}
+}
+
+// volumeTypeFromSource は短縮形のソースからマウント種別を判定します。
+// パス（"/", ".", "~" で始まる）であればバインドマウント、それ以外は名前付きボリュームです。
+func volumeTypeFromSource(source string) string {
+	if source == "" {
+		return "volume" // 匿名ボリューム
+	}
+	if strings.HasPrefix(source, "/") || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "~") {
+		return "bind"
+	}
+	return "volume"
+}
+
+// parseExpose は expose の一覧を解析します（"3000", 3000, "3000-3005", "3000/tcp" に対応）。
+func (p *YamlComposeParser) parseExpose(value interface{}) ([]int, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "exposeの形式が無効です",
+		}
+	}
+
+	var ports []int
+	for _, item := range items {
+		switch v := item.(type) {
+		case int:
+			ports = append(ports, v)
+		case string:
+			portPart, _, _ := strings.Cut(v, "/")
+			start, end, err := parsePortRange(portPart)
+			if err != nil {
+				return nil, &errors.AppError{
+					Code:    errors.ErrParseFailed,
+					Message: fmt.Sprintf("exposeの解析に失敗: %s", v),
+					Cause:   err,
+				}
+			}
+			for port := start; port <= end; port++ {
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	return ports, nil
+}
+
+// parsePortRange は "8080" または "8000-8010" 形式のポート指定を解析します。
+func parsePortRange(value string) (int, int, error) {
+	startStr, endStr, isRange := strings.Cut(strings.TrimSpace(value), "-")
+
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, start, nil
+	}
+
+	end, err := strconv.Atoi(endStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("ポート範囲の終端が始端より小さいです: %s", value)
+	}
+
+	return start, end, nil
+}
+
 // extractVersion はDockerComposeバージョンを抽出します。
 func (p *YamlComposeParser) extractVersion(raw map[string]interface{}) string {
 	if version, exists := raw["version"]; exists {
@@ -465,21 +887,123 @@ func (p *YamlComposeParser) parseEnvironment(env interface{}) map[string]string
 	return result
 }
 
+// parseEnvFiles は env_file を解析し、各ファイルの内容を読み込みます。
+// 文字列・文字列のリスト・path/required を持つ長形式に対応し、相対パスは baseDir を基準に解決します。
+// required: false のファイルが存在しない場合は空として扱います。
+func (p *YamlComposeParser) parseEnvFiles(ctx context.Context, value interface{}, baseDir string) ([]types.EnvFile, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case string:
+		items = []interface{}{v}
+	case []interface{}:
+		items = v
+	default:
+		return nil, &errors.AppError{
+			Code:    errors.ErrParseFailed,
+			Message: "env_file の形式が無効です",
+			Fields: map[string]interface{}{
+				"env_file_type": fmt.Sprintf("%T", value),
+			},
+		}
+	}
+
+	var envFiles []types.EnvFile
+	for _, item := range items {
+		envFile := types.EnvFile{Required: true}
+		switch v := item.(type) {
+		case string:
+			envFile.Path = v
+		case map[string]interface{}:
+			envFile.Path, _ = v["path"].(string)
+			if required, ok := v["required"].(bool); ok {
+				envFile.Required = required
+			}
+		}
+		if envFile.Path == "" {
+			return nil, &errors.AppError{
+				Code:    errors.ErrParseFailed,
+				Message: "env_file のパスが指定されていません",
+			}
+		}
+		if !filepath.IsAbs(envFile.Path) {
+			envFile.Path = filepath.Join(baseDir, envFile.Path)
+		}
+
+		values, err := readEnvFile(envFile.Path)
+		if err != nil {
+			if envFile.Required || !os.IsNotExist(err) {
+				p.logger.Warn(ctx, fmt.Sprintf("env_file %s を読み込めませんでした", envFile.Path),
+					types.Field{Key: "file", Value: envFile.Path},
+					types.Field{Key: "error", Value: err.Error()})
+			}
+			values = map[string]string{}
+		}
+		envFile.Values = values
+		envFiles = append(envFiles, envFile)
+	}
+	return envFiles, nil
+}
+
 // parseDependsOn は依存関係を解析します。
-func (p *YamlComposeParser) parseDependsOn(depends interface{}) []string {
-	var result []string
+// 短縮形（リスト）は condition: service_started、required: true として扱います。
+func (p *YamlComposeParser) parseDependsOn(depends interface{}) (map[string]types.ServiceDependency, error) {
+	result := make(map[string]types.ServiceDependency)
 
 	switch d := depends.(type) {
 	case []interface{}:
 		for _, item := range d {
 			if itemStr, ok := item.(string); ok {
-				result = append(result, itemStr)
+				result[itemStr] = types.ServiceDependency{
+					Condition: types.DependencyConditionStarted,
+					Required:  true,
+				}
 			}
 		}
 	case map[string]interface{}:
-		for key := range d {
-			result = append(result, key)
+		for key, value := range d {
+			dependency := types.ServiceDependency{
+				Condition: types.DependencyConditionStarted,
+				Required:  true,
+			}
+			if options, ok := value.(map[string]interface{}); ok {
+				if condition, ok := options["condition"].(string); ok {
+					switch condition {
+					case types.DependencyConditionStarted, types.DependencyConditionHealthy, types.DependencyConditionCompleted:
+						dependency.Condition = condition
+					default:
+						return nil, &errors.AppError{
+							Code:    errors.ErrParseFailed,
+							Message: fmt.Sprintf("depends_on %s のconditionが無効です: %s", key, condition),
+						}
+					}
+				}
+				if restart, ok := options["restart"].(bool); ok {
+					dependency.Restart = restart
+				}
+				if required, ok := options["required"].(bool); ok {
+					dependency.Required = required
+				}
+			}
+			result[key] = dependency
+		}
+	}
+
+	return result, nil
+}
+
+// parseStringList は文字列のリストを解析します。
+func (p *YamlComposeParser) parseStringList(value interface{}) []string {
+	var result []string
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if itemStr, ok := item.(string); ok {
+				result = append(result, itemStr)
+			}
 		}
+	case string:
+		result = append(result, v)
 	}
 
 	return result
@@ -501,7 +1025,7 @@ func (p *YamlComposeParser) parseNetworks(networks interface{}) map[string]types
 		// 詳細なネットワーク設定
 		for networkName, config := range n {
 			serviceNetwork := types.ServiceNetwork{}
-			
+
 			if configMap, ok := config.(map[string]interface{}); ok {
 				// IPv4アドレス設定
 				if ipv4, exists := configMap["ipv4_address"]; exists {
@@ -510,7 +1034,7 @@ func (p *YamlComposeParser) parseNetworks(networks interface{}) map[string]types
 					}
 				}
 			}
-			
+
 			result[networkName] = serviceNetwork
 		}
 	}
@@ -540,6 +1064,8 @@ func (d *ComposeFileDetectorImpl) DetectComposeFiles(ctx context.Context, direct
 		"docker-compose.yaml",
 		"compose.yml",
 		"compose.yaml",
+		"docker-compose.json",
+		"compose.json",
 	}
 
 	var foundFiles []string
@@ -581,6 +1107,28 @@ func (d *ComposeFileDetectorImpl) GetDefaultComposeFile(ctx context.Context, dir
 	return files[0], nil
 }
 
+// DetectOverrideFile はComposeが自動的に読み込むoverrideファイルを検出します。
+// docker compose は -f を指定しない場合、Composeファイルと同じディレクトリの
+// override ファイルを自動的にマージするため、その存在を把握しておく必要があります。
+func (d *ComposeFileDetectorImpl) DetectOverrideFile(ctx context.Context, directory string) (string, bool) {
+	candidates := []string{
+		"compose.override.yml",
+		"compose.override.yaml",
+		"docker-compose.override.yml",
+		"docker-compose.override.yaml",
+	}
+
+	for _, candidate := range candidates {
+		filePath := filepath.Join(directory, candidate)
+		if _, err := os.Stat(filePath); err == nil {
+			d.logger.Debug(ctx, "overrideファイル発見", types.Field{Key: "file", Value: filePath})
+			return filePath, true
+		}
+	}
+
+	return "", false
+}
+
 // convertToNetwork はネットワーク設定を変換します。
 func (p *YamlComposeParser) convertToNetwork(ctx context.Context, name string, networkMap map[string]interface{}) (types.Network, error) {
 	network := types.Network{
@@ -716,5 +1264,19 @@ func (p *YamlComposeParser) convertToVolume(ctx context.Context, name string, vo
 		}
 	}
 
+	// 固定名と外部ボリューム（旧形式の external: {name: ...} にも対応）
+	if volumeName, ok := volumeMap["name"].(string); ok {
+		volume.Name = volumeName
+	}
+	switch external := volumeMap["external"].(type) {
+	case bool:
+		volume.External = external
+	case map[string]interface{}:
+		volume.External = true
+		if externalName, ok := external["name"].(string); ok {
+			volume.Name = externalName
+		}
+	}
+
 	return volume, nil
 }
@@ -255,6 +255,7 @@ func (r *ConflictResolverImpl) resolveByAutoIncrement(ctx context.Context, confl
 
 		resolution := types.ConflictResolution{
 			ConflictPort: conflict.Port,
+			Protocol:     conflict.Protocol,
 			ResolvedPort: allocatedPort,
 			ServiceName:  conflict.ServiceName,
 			Strategy:     types.ResolutionStrategyAutoIncrement,
@@ -310,6 +311,7 @@ func (r *ConflictResolverImpl) resolveByRangeAllocation(ctx context.Context, con
 
 			resolution := types.ConflictResolution{
 				ConflictPort: conflict.Port,
+				Protocol:     conflict.Protocol,
 				ResolvedPort: resolvedPort,
 				ServiceName:  conflict.ServiceName,
 				Strategy:     types.ResolutionStrategyRangeAllocation,
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// ComposeVersion はインストールされているDocker Composeのバージョンです。
+type ComposeVersion struct {
+	Major int
+	Minor int
+	Patch int
+	// Raw は `docker compose version --short` が出力したバージョンです。
+	Raw string
+}
+
+// String はDocker Composeが出力したバージョンを返します。
+func (v ComposeVersion) String() string {
+	return v.Raw
+}
+
+// AtLeast はバージョンが major.minor.patch 以降かどうかを返します。
+func (v ComposeVersion) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// composeVersionPattern は "2.24.5"・"v2.24.5"・"2.24.5-desktop.1" のようなバージョンに一致します。
+var composeVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseComposeVersion は `docker compose version --short` の出力を解析します。
+func ParseComposeVersion(output string) (ComposeVersion, error) {
+	raw := strings.TrimSpace(output)
+	match := composeVersionPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return ComposeVersion{}, fmt.Errorf("Docker Composeのバージョンを解析できません: %q", raw)
+	}
+
+	version := ComposeVersion{Raw: raw}
+	version.Major, _ = strconv.Atoi(match[1])
+	version.Minor, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		version.Patch, _ = strconv.Atoi(match[3])
+	}
+	return version, nil
+}
+
+// DockerComposeVersionDetector はDocker Compose（CLIプラグイン）のバージョンを検出します。
+type DockerComposeVersionDetector struct {
+	logger logger.Logger
+}
+
+// NewDockerComposeVersionDetector は新しいDockerComposeVersionDetectorを作成します。
+func NewDockerComposeVersionDetector(l logger.Logger) *DockerComposeVersionDetector {
+	return &DockerComposeVersionDetector{logger: l}
+}
+
+// DetectVersion は `docker compose version --short` が出力したバージョンを返します。
+func (d *DockerComposeVersionDetector) DetectVersion(ctx context.Context) (ComposeVersion, error) {
+	out, err := exec.CommandContext(ctx, "docker", "compose", "version", "--short").Output()
+	if err != nil {
+		return ComposeVersion{}, err
+	}
+
+	version, err := ParseComposeVersion(string(out))
+	if err != nil {
+		return ComposeVersion{}, err
+	}
+
+	d.logger.Debug(ctx, "Docker Composeのバージョンを検出しました",
+		types.Field{Key: "version", Value: version.String()})
+	return version, nil
+}
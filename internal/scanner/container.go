@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/logger"
+)
+
+// composeProjectLabel はDocker Composeが作成したコンテナに付けるプロジェクト名のラベルです。
+const composeProjectLabel = "com.docker.compose.project"
+
+// ContainerInfo は既存のDockerコンテナの基本情報です。
+type ContainerInfo struct {
+	Name    string `json:"Name"`
+	Project string `json:"Project"`
+}
+
+// DockerContainerDetector は既存のDockerコンテナ（停止中を含む）を検出します。
+type DockerContainerDetector struct {
+	logger logger.Logger
+}
+
+// NewDockerContainerDetector は新しいDockerContainerDetectorを作成します。
+func NewDockerContainerDetector(l logger.Logger) *DockerContainerDetector {
+	return &DockerContainerDetector{logger: l}
+}
+
+// DetectContainers は全てのコンテナを、Composeのプロジェクト名のラベルとあわせて返します。
+func (d *DockerContainerDetector) DetectContainers(ctx context.Context) ([]ContainerInfo, error) {
+	out, err := exec.CommandContext(ctx, "docker", "ps", "-a",
+		"--format", `{{.Names}}\t{{.Label "`+composeProjectLabel+`"}}`).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []ContainerInfo
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		name, project, _ := strings.Cut(line, "\t")
+		containers = append(containers, ContainerInfo{
+			Name:    strings.TrimSpace(name),
+			Project: strings.TrimSpace(project),
+		})
+	}
+	return containers, nil
+}
+
+// publishedPortPattern は `docker ps` の出力の公開ポート（"0.0.0.0:8080->80/tcp"・":::8000-8002->8000-8002/tcp" など）に一致します。
+var publishedPortPattern = regexp.MustCompile(`:(\d+)(?:-(\d+))?->`)
+
+// DetectProjectPorts はComposeプロジェクトの実行中のコンテナが公開しているホストポートを返します。
+func (d *DockerContainerDetector) DetectProjectPorts(ctx context.Context, project string) ([]int, error) {
+	out, err := exec.CommandContext(ctx, "docker", "ps",
+		"--filter", "label="+composeProjectLabel+"="+project,
+		"--format", "{{.Ports}}").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var ports []int
+	for _, match := range publishedPortPattern.FindAllStringSubmatch(string(out), -1) {
+		start, _ := strconv.Atoi(match[1])
+		end := start
+		if match[2] != "" {
+			end, _ = strconv.Atoi(match[2])
+		}
+		for port := start; port <= end; port++ {
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
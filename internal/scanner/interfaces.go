@@ -18,6 +18,7 @@ type PortDetector interface {
 type PortAllocator interface {
 	AllocatePort(ctx context.Context, config types.PortConfig) (int, error)
 	AllocatePorts(ctx context.Context, count int, config types.PortConfig) ([]int, error)
+	AllocatePortBlock(ctx context.Context, size int, config types.PortConfig) (int, error)
 	AllocatePortsForServices(ctx context.Context, services []types.Service, config types.PortConfig) (map[string]int, error)
 }
 
@@ -57,6 +58,9 @@ type UnifiedConflictDetector interface {
 	DetectConflicts(ctx context.Context, config *types.ComposeConfig, projectName string) (*types.UnifiedConflictInfo, error)
 	DetectPortConflicts(ctx context.Context, config *types.ComposeConfig) ([]types.PortConflictInfo, error)
 	DetectNetworkConflicts(ctx context.Context, config *types.ComposeConfig, projectName string) ([]types.NetworkConflictInfo, error)
+	DetectHostNetworkConflicts(ctx context.Context, config *types.ComposeConfig) ([]types.HostNetworkConflictInfo, error)
+	DetectContainerNameConflicts(ctx context.Context, config *types.ComposeConfig, projectName string) ([]types.ContainerNameConflictInfo, error)
+	DetectVolumeConflicts(ctx context.Context, config *types.ComposeConfig, projectName string) ([]types.VolumeConflictInfo, error)
 }
 
 // NetworkDetector は既存Dockerネットワークの検知を行うインターフェースです。
@@ -64,6 +68,16 @@ type NetworkDetector interface {
 	DetectNetworks(ctx context.Context) ([]NetworkInfo, error)
 }
 
+// ContainerDetector は既存Dockerコンテナの検知を行うインターフェースです。
+type ContainerDetector interface {
+	DetectContainers(ctx context.Context) ([]ContainerInfo, error)
+}
+
+// VolumeDetector は既存Dockerボリュームの検知を行うインターフェースです。
+type VolumeDetector interface {
+	DetectVolumes(ctx context.Context) ([]VolumeInfo, error)
+}
+
 // AllocationStrategy はポート割り当て戦略を表します。
 type AllocationStrategy string
 
@@ -254,6 +254,62 @@ func (p *PortAllocatorImpl) AllocatePorts(ctx context.Context, count int, config
 	return allocatedPorts, nil
 }
 
+// AllocatePortBlock は連続した size 個の利用可能なポートを割り当て、先頭のポートを返します。
+// published の範囲指定（例: "8000-8010"）を同じ幅のまま移動するために使います。
+func (p *PortAllocatorImpl) AllocatePortBlock(ctx context.Context, size int, config types.PortConfig) (int, error) {
+	if size <= 1 {
+		return p.AllocatePort(ctx, config)
+	}
+
+	usedPorts, err := p.detector.DetectUsedPortsInRange(ctx, config.Range)
+	if err != nil {
+		return 0, err
+	}
+
+	// 使用中ポートと予約済みポートを合わせた除外リスト
+	excludePorts := make(map[int]bool)
+	for _, port := range usedPorts {
+		excludePorts[port] = true
+	}
+	for _, port := range config.Reserved {
+		excludePorts[port] = true
+	}
+
+	// 特権ポートを除外
+	if config.ExcludePrivileged {
+		for i := 1; i <= 1023; i++ {
+			excludePorts[i] = true
+		}
+	}
+
+	// 連続した空きポートを検索
+	run := 0
+	for port := config.Range.Start; port <= config.Range.End; port++ {
+		if excludePorts[port] {
+			run = 0
+			continue
+		}
+		run++
+		if run == size {
+			start := port - size + 1
+			p.logger.Debug(ctx, "ポートブロック割り当て成功",
+				types.Field{Key: "start", Value: start},
+				types.Field{Key: "end", Value: port})
+			return start, nil
+		}
+	}
+
+	return 0, &errors.AppError{
+		Code:    errors.ErrPortUnavailable,
+		Message: fmt.Sprintf("指定された範囲に連続した %d 個の利用可能なポートがありません", size),
+		Fields: map[string]interface{}{
+			"size":        size,
+			"range_start": config.Range.Start,
+			"range_end":   config.Range.End,
+		},
+	}
+}
+
 // AllocatePortsForServices はサービス別にポートを割り当てます。
 func (p *PortAllocatorImpl) AllocatePortsForServices(ctx context.Context, services []types.Service, config types.PortConfig) (map[string]int, error) {
 	// ポートが必要なサービス数を計算
@@ -3,6 +3,10 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/harakeishi/gopose/internal/logger"
@@ -11,17 +15,34 @@ import (
 
 // UnifiedConflictDetectorImpl は統一的な衝突検知の実装です。
 type UnifiedConflictDetectorImpl struct {
-	portDetector    PortDetector
-	networkDetector NetworkDetector
-	logger          logger.Logger
+	portDetector      PortDetector
+	networkDetector   NetworkDetector
+	containerDetector ContainerDetector
+	volumeDetector    VolumeDetector
+	hostNetwork       types.HostNetworkConfig
+	logger            logger.Logger
 }
 
 // NewUnifiedConflictDetectorImpl は新しいUnifiedConflictDetectorImplを作成します。
-func NewUnifiedConflictDetectorImpl(portDetector PortDetector, networkDetector NetworkDetector, logger logger.Logger) *UnifiedConflictDetectorImpl {
+func NewUnifiedConflictDetectorImpl(portDetector PortDetector, networkDetector NetworkDetector, containerDetector ContainerDetector, volumeDetector VolumeDetector, logger logger.Logger) *UnifiedConflictDetectorImpl {
 	return &UnifiedConflictDetectorImpl{
-		portDetector:    portDetector,
-		networkDetector: networkDetector,
-		logger:          logger,
+		portDetector:      portDetector,
+		networkDetector:   networkDetector,
+		containerDetector: containerDetector,
+		volumeDetector:    volumeDetector,
+		logger:            logger,
+	}
+}
+
+// NewUnifiedConflictDetectorWithHostNetworkConfig はホストネットワーク検知設定付きのUnifiedConflictDetectorImplを作成します。
+func NewUnifiedConflictDetectorWithHostNetworkConfig(portDetector PortDetector, networkDetector NetworkDetector, containerDetector ContainerDetector, volumeDetector VolumeDetector, hostNetwork types.HostNetworkConfig, logger logger.Logger) *UnifiedConflictDetectorImpl {
+	return &UnifiedConflictDetectorImpl{
+		portDetector:      portDetector,
+		networkDetector:   networkDetector,
+		containerDetector: containerDetector,
+		volumeDetector:    volumeDetector,
+		hostNetwork:       hostNetwork,
+		logger:            logger,
 	}
 }
 
@@ -40,6 +61,13 @@ func (u *UnifiedConflictDetectorImpl) DetectConflicts(ctx context.Context, confi
 	}
 	conflictInfo.PortConflicts = portConflicts
 
+	// ホストネットワークの衝突検知（解決不可のため報告のみ）
+	hostNetworkConflicts, err := u.DetectHostNetworkConflicts(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("ホストネットワーク衝突検知に失敗: %w", err)
+	}
+	conflictInfo.HostNetworkConflicts = hostNetworkConflicts
+
 	// ネットワーク衝突検知
 	networkConflicts, err := u.DetectNetworkConflicts(ctx, config, projectName)
 	if err != nil {
@@ -50,9 +78,30 @@ func (u *UnifiedConflictDetectorImpl) DetectConflicts(ctx context.Context, confi
 		conflictInfo.NetworkConflicts = networkConflicts
 	}
 
+	// コンテナ名衝突検知（Dockerが使えない環境ではスキップ）
+	containerNameConflicts, err := u.DetectContainerNameConflicts(ctx, config, projectName)
+	if err != nil {
+		u.logger.Warn(ctx, "コンテナ名衝突検知に失敗しました",
+			types.Field{Key: "error", Value: err.Error()})
+	} else {
+		conflictInfo.ContainerNameConflicts = containerNameConflicts
+	}
+
+	// 共有ボリューム検知
+	volumeConflicts, err := u.DetectVolumeConflicts(ctx, config, projectName)
+	if err != nil {
+		u.logger.Warn(ctx, "ボリューム衝突検知に失敗しました",
+			types.Field{Key: "error", Value: err.Error()})
+	} else {
+		conflictInfo.VolumeConflicts = volumeConflicts
+	}
+
 	u.logger.Info(ctx, "統一的な衝突検知完了",
 		types.Field{Key: "port_conflicts", Value: len(conflictInfo.PortConflicts)},
-		types.Field{Key: "network_conflicts", Value: len(conflictInfo.NetworkConflicts)})
+		types.Field{Key: "network_conflicts", Value: len(conflictInfo.NetworkConflicts)},
+		types.Field{Key: "host_network_conflicts", Value: len(conflictInfo.HostNetworkConflicts)},
+		types.Field{Key: "container_name_conflicts", Value: len(conflictInfo.ContainerNameConflicts)},
+		types.Field{Key: "volume_conflicts", Value: len(conflictInfo.VolumeConflicts)})
 
 	return conflictInfo, nil
 }
@@ -74,11 +123,26 @@ func (u *UnifiedConflictDetectorImpl) DetectPortConflicts(ctx context.Context, c
 		usedPortsMap[port] = true
 	}
 
-	// Compose内でのポート重複も検出
-	composePortsMap := make(map[int]string) // port -> service name
+	// Compose内でのポート重複も検出（プロトコルが異なる、または host_ip が重ならない場合は重複としない）
+	type composeBinding struct {
+		mapping types.PortMapping
+		service string
+	}
+	composeBindings := make(map[string][]composeBinding) // "ポート/プロトコル" -> 公開済みのバインド
+	bindingKey := func(port int, mapping types.PortMapping) string {
+		protocol := mapping.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		return fmt.Sprintf("%d/%s", port, protocol)
+	}
 
-	// 各サービスのポート設定を確認
-	for serviceName, service := range config.Services {
+	// 各サービスのポート設定を確認（Compose内の重複は名前順で先のサービスがポートを維持する）
+	for _, serviceName := range sortedServiceNames(config) {
+		service := config.Services[serviceName]
+		if service.UsesHostNetwork() {
+			continue // network_mode: host では ports は使われない（DetectHostNetworkConflicts で扱う）
+		}
 		for _, portMapping := range service.Ports {
 			if portMapping.Host == 0 {
 				continue // ホストポートが指定されていない場合はスキップ
@@ -87,30 +151,64 @@ func (u *UnifiedConflictDetectorImpl) DetectPortConflicts(ctx context.Context, c
 			conflict := types.PortConflictInfo{
 				Port:        portMapping.Host,
 				Protocol:    portMapping.Protocol,
+				HostIP:      portMapping.HostIP,
 				ServiceName: serviceName,
 				Service:     serviceName,
+				Source:      portMapping.Source,
+			}
+			if portMapping.IsRange() {
+				conflict.PortEnd = portMapping.HostEnd
+			}
+
+			// 範囲指定の場合は範囲内のいずれかのポートが衝突すればマッピング全体を衝突とみなす
+			systemPort, composePort := 0, 0
+			var existing composeBinding
+			for _, port := range portMapping.HostPorts() {
+				if usedPortsMap[port] {
+					systemPort = port
+					break
+				}
+				if composePort != 0 {
+					continue
+				}
+				for _, binding := range composeBindings[bindingKey(port, portMapping)] {
+					if hostIPsOverlap(binding.mapping.HostIP, portMapping.HostIP) {
+						composePort, existing = port, binding
+						break
+					}
+				}
 			}
 
 			// システムで使用中のポートとの衝突
-			if usedPortsMap[portMapping.Host] {
+			if systemPort != 0 {
 				conflict.Type = types.ConflictTypeSystem
-				conflict.Description = fmt.Sprintf("ポート %d は既にシステムで使用されています", portMapping.Host)
+				conflict.Description = fmt.Sprintf("ポート %d は既にシステムで使用されています%s",
+					systemPort, describeSource(portMapping.Source))
 				conflicts = append(conflicts, conflict)
 				u.logger.Warn(ctx, "システムポート衝突検出",
-					types.Field{Key: "port", Value: portMapping.Host},
-					types.Field{Key: "service", Value: serviceName})
-			} else if existingService, exists := composePortsMap[portMapping.Host]; exists {
+					types.Field{Key: "port", Value: systemPort},
+					types.Field{Key: "service", Value: serviceName},
+					types.Field{Key: "source", Value: portMapping.Source.String()})
+			} else if composePort != 0 {
 				// Compose内でのポート重複
+				existingPort := existing.mapping
+				existingService := existing.service
 				conflict.Type = types.ConflictTypeCompose
-				conflict.Description = fmt.Sprintf("ポート %d はサービス %s と %s で重複しています",
-					portMapping.Host, existingService, serviceName)
+				conflict.Description = fmt.Sprintf("ポート %d はサービス %s%s と %s%s で重複しています",
+					composePort, existingService, describeSource(existingPort.Source),
+					serviceName, describeSource(portMapping.Source))
 				conflicts = append(conflicts, conflict)
 				u.logger.Warn(ctx, "Composeポート衝突検出",
-					types.Field{Key: "port", Value: portMapping.Host},
+					types.Field{Key: "port", Value: composePort},
 					types.Field{Key: "service1", Value: existingService},
-					types.Field{Key: "service2", Value: serviceName})
+					types.Field{Key: "source1", Value: existingPort.Source.String()},
+					types.Field{Key: "service2", Value: serviceName},
+					types.Field{Key: "source2", Value: portMapping.Source.String()})
 			} else {
-				composePortsMap[portMapping.Host] = serviceName
+				for _, port := range portMapping.HostPorts() {
+					key := bindingKey(port, portMapping)
+					composeBindings[key] = append(composeBindings[key], composeBinding{mapping: portMapping, service: serviceName})
+				}
 			}
 		}
 	}
@@ -121,6 +219,15 @@ func (u *UnifiedConflictDetectorImpl) DetectPortConflicts(ctx context.Context, c
 	return conflicts, nil
 }
 
+// hostIPsOverlap は2つの host_ip が同じアドレスで待ち受けるかを返します。
+// 未指定（全アドレス）は全てのアドレスと重なります。
+func hostIPsOverlap(a, b string) bool {
+	isWildcard := func(ip string) bool {
+		return ip == "" || ip == "0.0.0.0" || ip == "::"
+	}
+	return isWildcard(a) || isWildcard(b) || a == b
+}
+
 // DetectNetworkConflicts はネットワーク衝突検知を実行します。
 func (u *UnifiedConflictDetectorImpl) DetectNetworkConflicts(ctx context.Context, config *types.ComposeConfig, projectName string) ([]types.NetworkConflictInfo, error) {
 	u.logger.Debug(ctx, "ネットワーク衝突検知開始")
@@ -148,8 +255,14 @@ func (u *UnifiedConflictDetectorImpl) DetectNetworkConflicts(ctx context.Context
 		projectPrefix = projectName + "_"
 	}
 
-	// Composeネットワークを確認
-	for netName, network := range config.Networks {
+	// Composeネットワークを確認（割り当てるサブネットが実行ごとに変わらないよう名前順）
+	netNames := make([]string, 0, len(config.Networks))
+	for netName := range config.Networks {
+		netNames = append(netNames, netName)
+	}
+	sort.Strings(netNames)
+	for _, netName := range netNames {
+		network := config.Networks[netName]
 		if len(network.IPAM.Config) == 0 {
 			continue
 		}
@@ -215,3 +328,233 @@ func (u *UnifiedConflictDetectorImpl) getServiceNetworkIPs(config *types.Compose
 
 	return serviceIPs
 }
+
+// describeSource は衝突メッセージに付ける定義元の表記を返します。
+func describeSource(source types.SourceLocation) string {
+	if source.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (定義元: %s)", source)
+}
+
+// hostNetworkGuidance は network_mode: host の衝突時に表示する対処方法です。
+const hostNetworkGuidance = "network_mode: host のサービスはホストのポートに直接バインドするため、overrideでポートを変更できません。" +
+	"他のワークツリーやプロセスを停止するか、ブリッジネットワークと ports: に切り替えるか、アプリケーション側の待ち受けポートを環境変数などで変更してください"
+
+// DetectHostNetworkConflicts は network_mode: host のサービスが直接使うポートの衝突を検知します。
+// expose と ports の target、設定された既知のポート（イメージ別）をホストのポートとみなします。
+func (u *UnifiedConflictDetectorImpl) DetectHostNetworkConflicts(ctx context.Context, config *types.ComposeConfig) ([]types.HostNetworkConflictInfo, error) {
+	var hostServices []string
+	for serviceName, service := range config.Services {
+		if service.UsesHostNetwork() {
+			hostServices = append(hostServices, serviceName)
+		}
+	}
+	if len(hostServices) == 0 {
+		return nil, nil
+	}
+	sort.Strings(hostServices)
+
+	usedPorts, err := u.portDetector.DetectUsedPorts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("システムポート検出に失敗: %w", err)
+	}
+	usedPortsMap := make(map[int]bool)
+	for _, port := range usedPorts {
+		usedPortsMap[port] = true
+	}
+
+	// ブリッジネットワークのサービスが公開しているホストポート
+	publishedPorts := make(map[int]string)
+	for serviceName, service := range config.Services {
+		if service.UsesHostNetwork() {
+			continue
+		}
+		for _, portMapping := range service.Ports {
+			for _, port := range portMapping.HostPorts() {
+				publishedPorts[port] = serviceName
+			}
+		}
+	}
+
+	var conflicts []types.HostNetworkConflictInfo
+	claimedPorts := make(map[int]string) // port -> host network service
+	for _, serviceName := range hostServices {
+		service := config.Services[serviceName]
+
+		for _, port := range u.hostNetworkPorts(service) {
+			conflict := types.HostNetworkConflictInfo{
+				Service:  serviceName,
+				Port:     port,
+				Type:     types.ConflictTypeHostNetwork,
+				Guidance: hostNetworkGuidance,
+				Source:   service.Source,
+			}
+
+			switch {
+			case usedPortsMap[port]:
+				conflict.Description = fmt.Sprintf("ホストネットワークのサービス %s%s が使うポート %d は既にシステムで使用されています",
+					serviceName, describeSource(service.Source), port)
+			case claimedPorts[port] != "":
+				conflict.Description = fmt.Sprintf("ホストネットワークのサービス %s と %s%s がポート %d で重複しています",
+					claimedPorts[port], serviceName, describeSource(service.Source), port)
+			case publishedPorts[port] != "":
+				conflict.Description = fmt.Sprintf("ホストネットワークのサービス %s%s が使うポート %d はサービス %s が公開しています",
+					serviceName, describeSource(service.Source), port, publishedPorts[port])
+			default:
+				claimedPorts[port] = serviceName
+				continue
+			}
+
+			conflicts = append(conflicts, conflict)
+			u.logger.Warn(ctx, "ホストネットワーク衝突検出",
+				types.Field{Key: "port", Value: port},
+				types.Field{Key: "service", Value: serviceName},
+				types.Field{Key: "source", Value: service.Source.String()})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// hostNetworkPorts は network_mode: host のサービスがホスト上で待ち受けるポートを返します。
+func (u *UnifiedConflictDetectorImpl) hostNetworkPorts(service types.Service) []int {
+	seen := make(map[int]bool)
+	var ports []int
+	add := func(port int) {
+		if port > 0 && !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+
+	for _, port := range service.Expose {
+		add(port)
+	}
+	for _, portMapping := range service.Ports {
+		add(portMapping.Container)
+	}
+	for _, port := range u.wellKnownPorts(service.Image) {
+		add(port)
+	}
+
+	sort.Ints(ports)
+	return ports
+}
+
+// wellKnownPorts はイメージの既知のポートを返します（同じイメージが複数指定されている場合は後の指定を優先）。
+func (u *UnifiedConflictDetectorImpl) wellKnownPorts(image string) []int {
+	repository := imageRepository(image)
+	var ports []int
+	for _, entry := range u.hostNetwork.WellKnownPorts {
+		if imageRepository(entry.Image) == repository {
+			ports = entry.Ports
+		}
+	}
+	return ports
+}
+
+// imageRepository はイメージ名からタグ・ダイジェスト・既定レジストリを取り除きます。
+// 例: "docker.io/library/postgres:16" -> "postgres"
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	image = strings.TrimPrefix(image, "docker.io/")
+	return strings.TrimPrefix(image, "library/")
+}
+
+// DetectContainerNameConflicts は container_name が既存コンテナと重複していないかを検知します。
+// 同じComposeプロジェクトのコンテナ（前回起動したもの）は衝突とみなしません。
+func (u *UnifiedConflictDetectorImpl) DetectContainerNameConflicts(ctx context.Context, config *types.ComposeConfig, projectName string) ([]types.ContainerNameConflictInfo, error) {
+	var serviceNames []string
+	for serviceName, service := range config.Services {
+		if service.ContainerName != "" {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return nil, nil
+	}
+	sort.Strings(serviceNames)
+
+	if u.containerDetector == nil {
+		return nil, nil
+	}
+	containers, err := u.containerDetector.DetectContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("既存Dockerコンテナの検出に失敗: %w", err)
+	}
+
+	existing := make(map[string]ContainerInfo, len(containers))
+	for _, container := range containers {
+		existing[container.Name] = container
+	}
+
+	projectName = ProjectName(projectName, config)
+
+	var conflicts []types.ContainerNameConflictInfo
+	for _, serviceName := range serviceNames {
+		service := config.Services[serviceName]
+		container, exists := existing[service.ContainerName]
+		if !exists || (container.Project != "" && container.Project == projectName) {
+			continue
+		}
+
+		description := fmt.Sprintf("コンテナ名 %s は既に使用されています%s", service.ContainerName, describeSource(service.Source))
+		if container.Project != "" {
+			description = fmt.Sprintf("コンテナ名 %s はプロジェクト %s のコンテナで既に使用されています%s",
+				service.ContainerName, container.Project, describeSource(service.Source))
+		}
+
+		conflicts = append(conflicts, types.ContainerNameConflictInfo{
+			Service:         serviceName,
+			ContainerName:   service.ContainerName,
+			ExistingProject: container.Project,
+			ProjectName:     projectName,
+			Type:            types.ConflictTypeContainerName,
+			Description:     description,
+			Source:          service.Source,
+		})
+		u.logger.Warn(ctx, "コンテナ名衝突検出",
+			types.Field{Key: "service", Value: serviceName},
+			types.Field{Key: "container_name", Value: service.ContainerName},
+			types.Field{Key: "existing_project", Value: container.Project})
+	}
+
+	return conflicts, nil
+}
+
+// ProjectName は docker compose と同じ規則でプロジェクト名を返します。
+// explicit（-p）、COMPOSE_PROJECT_NAME、Composeファイルのトップレベルの name:、最初のComposeファイルのディレクトリ名の順に決め、
+// ディレクトリ名は小文字にして英数字・'_'・'-' 以外を除きます（先頭は英数字）。
+func ProjectName(explicit string, config *types.ComposeConfig) string {
+	if explicit != "" {
+		return explicit
+	}
+	if name := os.Getenv("COMPOSE_PROJECT_NAME"); name != "" {
+		return name
+	}
+	if config != nil && config.Name != "" {
+		return config.Name
+	}
+
+	dir := "."
+	if config != nil && config.FilePath != "" && config.FilePath != "-" {
+		dir = filepath.Dir(config.FilePath)
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(dir)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimLeft(b.String(), "_-")
+}
@@ -0,0 +1,242 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/harakeishi/gopose/internal/logger"
+	"github.com/harakeishi/gopose/pkg/types"
+)
+
+// VolumeInfo は既存のDockerボリュームの基本情報です。
+type VolumeInfo struct {
+	Name    string `json:"Name"`
+	Project string `json:"Project"`
+}
+
+// DockerVolumeDetector は既存のDockerボリュームを検出します。
+type DockerVolumeDetector struct {
+	logger logger.Logger
+}
+
+// NewDockerVolumeDetector は新しいDockerVolumeDetectorを作成します。
+func NewDockerVolumeDetector(l logger.Logger) *DockerVolumeDetector {
+	return &DockerVolumeDetector{logger: l}
+}
+
+// DetectVolumes は全てのボリュームを、Composeのプロジェクト名のラベルとあわせて返します。
+func (d *DockerVolumeDetector) DetectVolumes(ctx context.Context) ([]VolumeInfo, error) {
+	out, err := exec.CommandContext(ctx, "docker", "volume", "ls",
+		"--format", `{{.Name}}\t{{.Label "`+composeProjectLabel+`"}}`).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []VolumeInfo
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		name, project, _ := strings.Cut(line, "\t")
+		volumes = append(volumes, VolumeInfo{
+			Name:    strings.TrimSpace(name),
+			Project: strings.TrimSpace(project),
+		})
+	}
+	return volumes, nil
+}
+
+// sharedSystemPaths は共有されても状態が壊れないシステムのパスです（バインドマウントの検知対象外）。
+var sharedSystemPaths = []string{"/var/run", "/run", "/etc", "/dev", "/sys", "/proc"}
+
+// DetectVolumeConflicts はプロジェクト間で共有されるボリュームを検知します。
+//   - name: で固定された名前付きボリュームが他のプロジェクトに作成済み（プロジェクト別の名前で解決可能）
+//   - external: true のボリューム（全ワークツリーで共有されるため警告）
+//   - 書き込み可能な絶対パスへのバインドマウント（全ワークツリーで共有されるため警告）
+func (u *UnifiedConflictDetectorImpl) DetectVolumeConflicts(ctx context.Context, config *types.ComposeConfig, projectName string) ([]types.VolumeConflictInfo, error) {
+	projectName = ProjectName(projectName, config)
+
+	// ボリュームのキー -> 使用しているサービス、バインドパス -> 使用しているサービス
+	volumeUsers := make(map[string][]string)
+	bindUsers := make(map[string][]string)
+	bindSources := make(map[string]types.ServiceVolume)
+	for _, serviceName := range sortedServiceNames(config) {
+		for _, mount := range config.Services[serviceName].Volumes {
+			switch mount.Type {
+			case "volume":
+				if mount.Source != "" {
+					volumeUsers[mount.Source] = append(volumeUsers[mount.Source], serviceName)
+				}
+			case "bind":
+				hostPath, ok := sharedBindPath(mount.Source)
+				if !ok || mount.ReadOnly {
+					continue
+				}
+				if !containsName(bindUsers[hostPath], serviceName) {
+					bindUsers[hostPath] = append(bindUsers[hostPath], serviceName)
+				}
+				if _, exists := bindSources[hostPath]; !exists {
+					bindSources[hostPath] = mount
+				}
+			}
+		}
+	}
+
+	var conflicts []types.VolumeConflictInfo
+
+	// 名前付きボリューム
+	existingVolumes := u.existingVolumes(ctx)
+	volumeKeys := make([]string, 0, len(config.Volumes))
+	for key := range config.Volumes {
+		volumeKeys = append(volumeKeys, key)
+	}
+	sort.Strings(volumeKeys)
+
+	for _, key := range volumeKeys {
+		volume := config.Volumes[key]
+		services := volumeUsers[key]
+
+		switch {
+		case volume.External:
+			actualName := volume.Name
+			if actualName == "" {
+				actualName = key
+			}
+			if len(services) == 0 {
+				continue
+			}
+			conflicts = append(conflicts, types.VolumeConflictInfo{
+				VolumeName:  key,
+				ActualName:  actualName,
+				Kind:        types.VolumeConflictKindExternal,
+				Services:    services,
+				ProjectName: projectName,
+				Severity:    types.SeverityError,
+				Description: fmt.Sprintf("外部ボリューム %s (external: true) は全てのワークツリーで共有されます%s",
+					actualName, describeSource(volume.Source)),
+				Source: volume.Source,
+			})
+		case volume.Name != "":
+			existing, exists := existingVolumes[volume.Name]
+			if !exists || (existing.Project != "" && existing.Project == projectName) {
+				continue
+			}
+			description := fmt.Sprintf("ボリューム名 %s は既に作成済みで、他のワークツリーと共有されます%s",
+				volume.Name, describeSource(volume.Source))
+			if existing.Project != "" {
+				description = fmt.Sprintf("ボリューム名 %s はプロジェクト %s で作成済みで、共有されます%s",
+					volume.Name, existing.Project, describeSource(volume.Source))
+			}
+			conflicts = append(conflicts, types.VolumeConflictInfo{
+				VolumeName:      key,
+				ActualName:      volume.Name,
+				Kind:            types.VolumeConflictKindNamed,
+				Services:        services,
+				ExistingProject: existing.Project,
+				ProjectName:     projectName,
+				Severity:        types.SeverityWarning,
+				Description:     description,
+				Source:          volume.Source,
+			})
+		}
+	}
+
+	// バインドマウント
+	bindPaths := make([]string, 0, len(bindUsers))
+	for hostPath := range bindUsers {
+		bindPaths = append(bindPaths, hostPath)
+	}
+	sort.Strings(bindPaths)
+
+	for _, hostPath := range bindPaths {
+		mount := bindSources[hostPath]
+		conflicts = append(conflicts, types.VolumeConflictInfo{
+			VolumeName:  mount.Source,
+			ActualName:  hostPath,
+			Kind:        types.VolumeConflictKindBind,
+			Services:    bindUsers[hostPath],
+			ProjectName: projectName,
+			Severity:    types.SeverityError,
+			Description: fmt.Sprintf("ホストパス %s へのバインドマウントは全てのワークツリーで共有されます%s",
+				hostPath, describeSource(mount.Location)),
+			Source: mount.Location,
+		})
+	}
+
+	for _, conflict := range conflicts {
+		u.logger.Warn(ctx, "共有ボリューム検出",
+			types.Field{Key: "volume", Value: conflict.ActualName},
+			types.Field{Key: "kind", Value: conflict.Kind},
+			types.Field{Key: "services", Value: conflict.Services})
+	}
+
+	return conflicts, nil
+}
+
+// existingVolumes は既存のDockerボリュームを名前で引けるようにして返します。
+// Dockerが使えない場合は空として扱います（固定名ボリュームの検知のみスキップ）。
+func (u *UnifiedConflictDetectorImpl) existingVolumes(ctx context.Context) map[string]VolumeInfo {
+	result := make(map[string]VolumeInfo)
+	if u.volumeDetector == nil {
+		return result
+	}
+
+	volumes, err := u.volumeDetector.DetectVolumes(ctx)
+	if err != nil {
+		u.logger.Warn(ctx, "既存Dockerボリュームの検出に失敗しました",
+			types.Field{Key: "error", Value: err.Error()})
+		return result
+	}
+	for _, volume := range volumes {
+		result[volume.Name] = volume
+	}
+	return result
+}
+
+// sharedBindPath はワークツリー間で共有されるバインドマウントのホストパスを返します。
+// 相対パスはワークツリーごとに異なるため対象外です。
+func sharedBindPath(source string) (string, bool) {
+	hostPath := source
+	if strings.HasPrefix(hostPath, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		hostPath = filepath.Join(home, strings.TrimPrefix(hostPath, "~"))
+	}
+	if !filepath.IsAbs(hostPath) {
+		return "", false
+	}
+
+	hostPath = filepath.Clean(hostPath)
+	for _, systemPath := range sharedSystemPaths {
+		if hostPath == systemPath || strings.HasPrefix(hostPath, systemPath+"/") {
+			return "", false
+		}
+	}
+	return hostPath, true
+}
+
+// sortedServiceNames はサービス名を名前順に返します。
+func sortedServiceNames(config *types.ComposeConfig) []string {
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsName(names []string, target string) bool {
+	for _, name := range names {
+		if name == target {
+			return true
+		}
+	}
+	return false
+}
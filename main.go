@@ -9,9 +9,17 @@ import (
 	"github.com/harakeishi/gopose/cmd"
 )
 
+// ビルド時に -ldflags "-X main.version=..." で設定されます。
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
 func main() {
 	ctx := context.Background()
 
+	cmd.SetVersion(version, commit, date)
 	if err := cmd.Execute(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -1,24 +1,108 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Service はDocker Composeサービスを表します。
 type Service struct {
-	Name        string                     `yaml:"name" json:"name"`
-	Image       string                     `yaml:"image" json:"image"`
-	Ports       []PortMapping              `yaml:"ports" json:"ports"`
-	DependsOn   []string                   `yaml:"depends_on" json:"depends_on"`
-	Environment map[string]string          `yaml:"environment" json:"environment"`
-	Networks    map[string]ServiceNetwork  `yaml:"networks" json:"networks"`
+	Name      string        `yaml:"name" json:"name"`
+	Image     string        `yaml:"image" json:"image"`
+	Ports     []PortMapping `yaml:"ports" json:"ports"`
+	DependsOn []string      `yaml:"depends_on" json:"depends_on"`
+	// Dependencies は depends_on の依存先ごとの条件です（短縮形は service_started として扱います）。
+	Dependencies map[string]ServiceDependency `yaml:"-" json:"dependencies,omitempty"`
+	Environment  map[string]string            `yaml:"environment" json:"environment"`
+	// EnvFiles は env_file で読み込むファイルと、その内容です（environment の値が優先されます）。
+	EnvFiles    []EnvFile                 `yaml:"env_file,omitempty" json:"env_file,omitempty"`
+	Networks    map[string]ServiceNetwork `yaml:"networks" json:"networks"`
+	Profiles    []string                  `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	NetworkMode string                    `yaml:"network_mode,omitempty" json:"network_mode,omitempty"`
+	Expose      []int                     `yaml:"expose,omitempty" json:"expose,omitempty"`
+	// ContainerName は固定のコンテナ名です（未指定の場合はComposeが <project>-<service>-N を付けます）。
+	ContainerName string          `yaml:"container_name,omitempty" json:"container_name,omitempty"`
+	Links         []string        `yaml:"links,omitempty" json:"links,omitempty"`
+	ExternalLinks []string        `yaml:"external_links,omitempty" json:"external_links,omitempty"`
+	VolumesFrom   []string        `yaml:"volumes_from,omitempty" json:"volumes_from,omitempty"`
+	Volumes       []ServiceVolume `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	Source        SourceLocation  `yaml:"-" json:"source,omitempty"`
+}
+
+// ServiceDependency は depends_on の長形式の設定を表します。
+type ServiceDependency struct {
+	// Condition は service_started / service_healthy / service_completed_successfully のいずれかです。
+	Condition string `yaml:"condition" json:"condition"`
+	Restart   bool   `yaml:"restart,omitempty" json:"restart,omitempty"`
+	Required  bool   `yaml:"required" json:"required"`
+}
+
+// Dependency condition の値です。
+const (
+	DependencyConditionStarted   = "service_started"
+	DependencyConditionHealthy   = "service_healthy"
+	DependencyConditionCompleted = "service_completed_successfully"
+)
+
+// EnvFile は env_file で指定された環境変数ファイルを表します。
+type EnvFile struct {
+	// Path はComposeファイルのディレクトリを基準に解決したパスです。
+	Path     string `yaml:"path" json:"path"`
+	Required bool   `yaml:"required" json:"required"`
+	// Values はファイルから読み込んだ KEY=VALUE です（ファイルが無い場合は空）。
+	Values map[string]string `yaml:"-" json:"-"`
+}
+
+// ServiceVolume はサービスのボリュームマウントを表します。
+type ServiceVolume struct {
+	// Type は volume / bind / tmpfs などのマウント種別です。
+	Type string `yaml:"type" json:"type"`
+	// Source は名前付きボリュームのキー、またはバインドマウントのホストパスです。
+	Source   string         `yaml:"source,omitempty" json:"source,omitempty"`
+	Target   string         `yaml:"target" json:"target"`
+	ReadOnly bool           `yaml:"read_only,omitempty" json:"read_only,omitempty"`
+	Location SourceLocation `yaml:"-" json:"location,omitempty"`
+}
+
+// UsesHostNetwork はサービスがホストのネットワークを直接使うかどうかを返します。
+func (s Service) UsesHostNetwork() bool {
+	return s.NetworkMode == "host"
+}
+
+// SourceLocation は設定が定義されたファイル上の位置を表します。
+type SourceLocation struct {
+	File   string `yaml:"-" json:"file,omitempty"`
+	Line   int    `yaml:"-" json:"line,omitempty"`
+	Column int    `yaml:"-" json:"column,omitempty"`
+}
+
+// String は定義元を file:line:col 形式の文字列にします。
+func (l SourceLocation) String() string {
+	switch {
+	case l.Line > 0 && l.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+	case l.Line > 0:
+		return fmt.Sprintf("%s:%d", l.File, l.Line)
+	default:
+		return l.File
+	}
+}
+
+// IsZero は定義元が記録されていないかを返します。
+func (l SourceLocation) IsZero() bool {
+	return l.File == ""
 }
 
 // ComposeConfig はDocker Composeファイルの設定を表します。
 type ComposeConfig struct {
-	Version  string             `yaml:"version" json:"version"`
-	Services map[string]Service `yaml:"services" json:"services"`
-	Networks map[string]Network `yaml:"networks" json:"networks"`
-	Volumes  map[string]Volume  `yaml:"volumes" json:"volumes"`
-	FilePath string             `yaml:"-" json:"file_path"`
+	// Name はトップレベルの name:（プロジェクト名）です。
+	Name      string             `yaml:"name,omitempty" json:"name,omitempty"`
+	Version   string             `yaml:"version" json:"version"`
+	Services  map[string]Service `yaml:"services" json:"services"`
+	Networks  map[string]Network `yaml:"networks" json:"networks"`
+	Volumes   map[string]Volume  `yaml:"volumes" json:"volumes"`
+	FilePath  string             `yaml:"-" json:"file_path"`
+	FilePaths []string           `yaml:"-" json:"file_paths,omitempty"`
 }
 
 // Network はDocker Composeネットワーク設定を表します。
@@ -26,6 +110,7 @@ type Network struct {
 	Driver string            `yaml:"driver" json:"driver"`
 	IPAM   IPAM              `yaml:"ipam" json:"ipam"`
 	Labels map[string]string `yaml:"labels" json:"labels"`
+	Source SourceLocation    `yaml:"-" json:"source,omitempty"`
 }
 
 // IPAM はIPアドレス管理設定を表します。
@@ -45,6 +130,15 @@ type Volume struct {
 	Driver     string            `yaml:"driver" json:"driver"`
 	DriverOpts map[string]string `yaml:"driver_opts" json:"driver_opts"`
 	Labels     map[string]string `yaml:"labels" json:"labels"`
+	// Name は固定のボリューム名です（未指定の場合はComposeが <project>_<key> を付けます）。
+	Name     string         `yaml:"name,omitempty" json:"name,omitempty"`
+	External bool           `yaml:"external,omitempty" json:"external,omitempty"`
+	Source   SourceLocation `yaml:"-" json:"source,omitempty"`
+}
+
+// VolumeOverride はボリューム設定のオーバーライドを表します。
+type VolumeOverride struct {
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
 }
 
 // OverrideConfig はoverride.ymlファイルの構造を表します。
@@ -53,13 +147,34 @@ type OverrideConfig struct {
 	Version  string                     `yaml:"version,omitempty" json:"version,omitempty"`
 	Services map[string]ServiceOverride `yaml:"services" json:"services"`
 	Networks map[string]NetworkOverride `yaml:"networks,omitempty" json:"networks,omitempty"`
+	Volumes  map[string]VolumeOverride  `yaml:"volumes,omitempty" json:"volumes,omitempty"`
 	Metadata OverrideMetadata           `yaml:"x-gopose-metadata" json:"metadata"`
+	// TemplateContent はテンプレートから描画した追加の設定（YAML）です。出力時に生成内容とマージされます。
+	TemplateContent []byte `yaml:"-" json:"-"`
 }
 
 // ServiceOverride はサービスのオーバーライド設定を表します。
 type ServiceOverride struct {
-	Ports    []PortMapping              `yaml:"ports" json:"ports"`
-	Networks map[string]ServiceNetwork  `yaml:"networks" json:"networks"`
+	Ports    []PortMapping             `yaml:"ports" json:"ports"`
+	Networks map[string]ServiceNetwork `yaml:"networks" json:"networks"`
+	// ContainerName 以下は container_name の付け替えと、それを参照する設定の書き換えです。
+	ContainerName string   `yaml:"container_name,omitempty" json:"container_name,omitempty"`
+	NetworkMode   string   `yaml:"network_mode,omitempty" json:"network_mode,omitempty"`
+	Links         []string `yaml:"links,omitempty" json:"links,omitempty"`
+	ExternalLinks []string `yaml:"external_links,omitempty" json:"external_links,omitempty"`
+	VolumesFrom   []string `yaml:"volumes_from,omitempty" json:"volumes_from,omitempty"`
+	// Environment は付け替えたホストポートに合わせて書き換えた環境変数です（キー単位でマージされます）。
+	Environment map[string]string `yaml:"environment,omitempty" json:"environment,omitempty"`
+}
+
+// EnvironmentRewrite は付け替えたホストポートに合わせた環境変数の書き換えを表します。
+type EnvironmentRewrite struct {
+	Service  string `json:"service"`
+	Variable string `json:"variable"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	// Source は値の定義元です（environment、または env_file のパス）。
+	Source string `json:"source"`
 }
 
 // ServiceNetwork はサービスのネットワーク設定を表します。
@@ -68,10 +183,46 @@ type ServiceNetwork struct {
 }
 
 // OverrideMetadata は生成情報とメタデータを表します。
+// override の x-gopose-metadata に書き込まれ、gopose が生成したファイルの識別に使われます。
 type OverrideMetadata struct {
-	GeneratedAt time.Time            `yaml:"generated_at" json:"generated_at"`
-	Version     string               `yaml:"version" json:"version"`
-	Resolutions []ConflictResolution `yaml:"resolutions" json:"resolutions"`
+	GeneratedBy string    `yaml:"generated_by" json:"generated_by"`
+	Version     string    `yaml:"version" json:"version"`
+	GeneratedAt time.Time `yaml:"generated_at" json:"generated_at"`
+	Project     string    `yaml:"project,omitempty" json:"project,omitempty"`
+	// Sources は生成に使ったComposeファイルと、その内容のハッシュです。
+	Sources []SourceFile `yaml:"sources,omitempty" json:"sources,omitempty"`
+	// Fingerprint はComposeファイル・参照している環境変数などの入力全体のハッシュです（変更の検知に使います）。
+	Fingerprint string `yaml:"fingerprint,omitempty" json:"fingerprint,omitempty"`
+	// GeneratedFiles はoverrideとあわせてテンプレートから生成したファイルです（clean で削除されます）。
+	GeneratedFiles []GeneratedFile `yaml:"generated_files,omitempty" json:"generated_files,omitempty"`
+	// ListSyntax はリストの置き換え方です（override または reset。Composeのバージョンで決まります）。
+	ListSyntax string `yaml:"list_syntax,omitempty" json:"list_syntax,omitempty"`
+	// Checksum はメタデータより前の本文の SHA-256 です（生成後の手動編集の検知に使います）。
+	Checksum           string               `yaml:"checksum,omitempty" json:"checksum,omitempty"`
+	Resolutions        []ConflictResolution `yaml:"resolutions" json:"resolutions"`
+	NetworkResolutions []NetworkResolution  `yaml:"network_resolutions,omitempty" json:"network_resolutions,omitempty"`
+}
+
+// GeneratedFile はテンプレートから生成したファイルと、その内容の SHA-256（16進数）を表します。
+type GeneratedFile struct {
+	Path     string `yaml:"path" json:"path"`
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+	SHA256   string `yaml:"sha256" json:"sha256"`
+}
+
+// SourceFile は生成に使ったファイルと、その内容の SHA-256（16進数）を表します。
+type SourceFile struct {
+	Path   string `yaml:"path" json:"path"`
+	SHA256 string `yaml:"sha256,omitempty" json:"sha256,omitempty"`
+}
+
+// NetworkResolution はメタデータに記録するネットワーク衝突の解決内容を表します。
+type NetworkResolution struct {
+	Network        string            `yaml:"network" json:"network"`
+	OriginalSubnet string            `yaml:"original_subnet" json:"original_subnet"`
+	ResolvedSubnet string            `yaml:"resolved_subnet" json:"resolved_subnet"`
+	ServiceIPs     map[string]string `yaml:"service_ips,omitempty" json:"service_ips,omitempty"`
+	Reason         string            `yaml:"reason" json:"reason"`
 }
 
 // NetworkOverride はネットワーク設定のオーバーライドを表します。
@@ -8,48 +8,80 @@ type Config interface {
 	GetFile() FileConfig
 	GetWatcher() WatcherConfig
 	GetLog() LogConfig
+	GetHostNetwork() HostNetworkConfig
 	Validate() error
 }
 
 // PortConfig はポート関連設定を表します。
 type PortConfig struct {
-	Range             PortRange `yaml:"range" json:"range"`
-	Reserved          []int     `yaml:"reserved" json:"reserved"`
-	ExcludePrivileged bool      `yaml:"exclude_privileged" json:"exclude_privileged"`
+	Range             PortRange `yaml:"range" json:"range" mapstructure:"range"`
+	Reserved          []int     `yaml:"reserved" json:"reserved" mapstructure:"reserved"`
+	ExcludePrivileged bool      `yaml:"exclude_privileged" json:"exclude_privileged" mapstructure:"exclude_privileged"`
 }
 
 // FileConfig はファイル関連設定を表します。
 type FileConfig struct {
-	ComposeFile   string `yaml:"compose_file" json:"compose_file"`
-	OverrideFile  string `yaml:"override_file" json:"override_file"`
-	BackupEnabled bool   `yaml:"backup_enabled" json:"backup_enabled"`
-	BackupDir     string `yaml:"backup_dir" json:"backup_dir"`
+	ComposeFile   string `yaml:"compose_file" json:"compose_file" mapstructure:"compose_file"`
+	OverrideFile  string `yaml:"override_file" json:"override_file" mapstructure:"override_file"`
+	BackupEnabled bool   `yaml:"backup_enabled" json:"backup_enabled" mapstructure:"backup_enabled"`
+	BackupDir     string `yaml:"backup_dir" json:"backup_dir" mapstructure:"backup_dir"`
+	// BackupRetention を過ぎたバックアップは新しいバックアップの作成時に削除されます（0 の場合は削除しません）。
+	BackupRetention time.Duration `yaml:"backup_retention" json:"backup_retention" mapstructure:"backup_retention"`
+	// OverrideTemplate は生成したoverrideにマージするテンプレート（text/template）のパスです。
+	OverrideTemplate string `yaml:"override_template" json:"override_template" mapstructure:"override_template"`
+	// OverrideFormat はoverrideの出力形式です（yaml または json、空の場合は出力先の拡張子から決めます）。
+	OverrideFormat string `yaml:"override_format" json:"override_format" mapstructure:"override_format"`
+	// Templates はoverrideの生成後に描画する設定ファイルのテンプレートと出力先です。
+	Templates []TemplateFile `yaml:"templates" json:"templates" mapstructure:"templates"`
+	// Parser はComposeファイルの解析バックエンドです（yaml または docker）。
+	Parser string `yaml:"parser" json:"parser" mapstructure:"parser"`
+}
+
+// TemplateFile は描画するテンプレートと出力先を表します（相対パスはComposeファイルのディレクトリが基準です）。
+type TemplateFile struct {
+	Template string `yaml:"template" json:"template" mapstructure:"template"`
+	Output   string `yaml:"output" json:"output" mapstructure:"output"`
 }
 
 // WatcherConfig は監視関連設定を表します。
 type WatcherConfig struct {
-	Interval      time.Duration `yaml:"interval" json:"interval"`
-	CleanupDelay  time.Duration `yaml:"cleanup_delay" json:"cleanup_delay"`
-	MaxRetries    int           `yaml:"max_retries" json:"max_retries"`
-	RetryInterval time.Duration `yaml:"retry_interval" json:"retry_interval"`
+	Interval      time.Duration `yaml:"interval" json:"interval" mapstructure:"interval"`
+	CleanupDelay  time.Duration `yaml:"cleanup_delay" json:"cleanup_delay" mapstructure:"cleanup_delay"`
+	MaxRetries    int           `yaml:"max_retries" json:"max_retries" mapstructure:"max_retries"`
+	RetryInterval time.Duration `yaml:"retry_interval" json:"retry_interval" mapstructure:"retry_interval"`
 }
 
 // LogConfig はログ関連設定を表します。
 type LogConfig struct {
-	Level    string `yaml:"level" json:"level"`
-	Format   string `yaml:"format" json:"format"`
-	File     string `yaml:"file" json:"file"`
-	MaxSize  int    `yaml:"max_size" json:"max_size"`
-	MaxAge   int    `yaml:"max_age" json:"max_age"`
-	Compress bool   `yaml:"compress" json:"compress"`
+	Level    string `yaml:"level" json:"level" mapstructure:"level"`
+	Format   string `yaml:"format" json:"format" mapstructure:"format"`
+	File     string `yaml:"file" json:"file" mapstructure:"file"`
+	MaxSize  int    `yaml:"max_size" json:"max_size" mapstructure:"max_size"`
+	MaxAge   int    `yaml:"max_age" json:"max_age" mapstructure:"max_age"`
+	Compress bool   `yaml:"compress" json:"compress" mapstructure:"compress"`
+}
+
+// HostNetworkConfig は network_mode: host のサービスの検知設定を表します。
+type HostNetworkConfig struct {
+	// WellKnownPorts はイメージごとの待ち受けポートです（expose が無くても検知対象にします）。
+	// イメージ名には "." が含まれるため（ghcr.io/org/db など）、設定のキーではなくリストで指定します。
+	WellKnownPorts []WellKnownPort `yaml:"well_known_ports" json:"well_known_ports" mapstructure:"well_known_ports"`
+}
+
+// WellKnownPort はイメージが待ち受ける既知のポートを表します。
+type WellKnownPort struct {
+	// Image はイメージ名です（タグ・ダイジェスト・docker.io/library/ は比較時に取り除きます）。
+	Image string `yaml:"image" json:"image" mapstructure:"image"`
+	Ports []int  `yaml:"ports" json:"ports" mapstructure:"ports"`
 }
 
 // AppConfig は具体的な設定実装です。
 type AppConfig struct {
-	Port    PortConfig    `yaml:"port" json:"port"`
-	File    FileConfig    `yaml:"file" json:"file"`
-	Watcher WatcherConfig `yaml:"watcher" json:"watcher"`
-	Log     LogConfig     `yaml:"log" json:"log"`
+	Port        PortConfig        `yaml:"port" json:"port" mapstructure:"port"`
+	File        FileConfig        `yaml:"file" json:"file" mapstructure:"file"`
+	Watcher     WatcherConfig     `yaml:"watcher" json:"watcher" mapstructure:"watcher"`
+	Log         LogConfig         `yaml:"log" json:"log" mapstructure:"log"`
+	HostNetwork HostNetworkConfig `yaml:"host_network" json:"host_network" mapstructure:"host_network"`
 }
 
 // GetPort はポート設定を返します。
@@ -72,6 +104,11 @@ func (c *AppConfig) GetLog() LogConfig {
 	return c.Log
 }
 
+// GetHostNetwork はホストネットワーク検知設定を返します。
+func (c *AppConfig) GetHostNetwork() HostNetworkConfig {
+	return c.HostNetwork
+}
+
 // Validate は設定の妥当性を検証します。
 func (c *AppConfig) Validate() error {
 	// TODO: 設定のバリデーションロジックを実装
@@ -6,7 +6,13 @@ import "time"
 type UnifiedConflictInfo struct {
 	PortConflicts    []PortConflictInfo    `json:"port_conflicts"`
 	NetworkConflicts []NetworkConflictInfo `json:"network_conflicts"`
-	GeneratedAt      time.Time             `json:"generated_at"`
+	// HostNetworkConflicts は override では解決できない network_mode: host の衝突です。
+	HostNetworkConflicts []HostNetworkConflictInfo `json:"host_network_conflicts,omitempty"`
+	// ContainerNameConflicts は既存コンテナと重複する container_name の衝突です。
+	ContainerNameConflicts []ContainerNameConflictInfo `json:"container_name_conflicts,omitempty"`
+	// VolumeConflicts はプロジェクト間で共有されるボリューム・バインドマウントです。
+	VolumeConflicts []VolumeConflictInfo `json:"volume_conflicts,omitempty"`
+	GeneratedAt     time.Time            `json:"generated_at"`
 }
 
 // PortConflictInfo はポート衝突情報を表します。
@@ -14,9 +20,12 @@ type PortConflictInfo struct {
 	Service     string              `json:"service"`
 	ServiceName string              `json:"service_name"` // エイリアス
 	Port        int                 `json:"port"`
+	PortEnd     int                 `json:"port_end,omitempty"` // 範囲指定の場合の終端
 	Protocol    string              `json:"protocol"`
+	HostIP      string              `json:"host_ip,omitempty"`
 	Type        ConflictType        `json:"type"`
 	Description string              `json:"description"`
+	Source      SourceLocation      `json:"source,omitempty"`
 	Resolution  *PortResolutionInfo `json:"resolution,omitempty"`
 }
 
@@ -32,6 +41,77 @@ type NetworkConflictInfo struct {
 	ServiceIPs         map[string]string      `json:"service_ips,omitempty"`
 }
 
+// HostNetworkConflictInfo は network_mode: host のサービスが直接使うポートの衝突情報を表します。
+// ホストのポートに直接バインドされるため、ポートの付け替えでは解決できません。
+type HostNetworkConflictInfo struct {
+	Service     string         `json:"service"`
+	Port        int            `json:"port"`
+	Type        ConflictType   `json:"type"`
+	Description string         `json:"description"`
+	Guidance    string         `json:"guidance"`
+	Source      SourceLocation `json:"source,omitempty"`
+}
+
+// ContainerNameConflictInfo は container_name の衝突情報を表します。
+type ContainerNameConflictInfo struct {
+	Service       string `json:"service"`
+	ContainerName string `json:"container_name"`
+	// ExistingProject は同名の既存コンテナが属するComposeプロジェクトです（判別できた場合）。
+	ExistingProject string `json:"existing_project,omitempty"`
+	// ProjectName は付け替え後の名前に付与するプロジェクト名です。
+	ProjectName string                       `json:"project_name"`
+	Type        ConflictType                 `json:"type"`
+	Description string                       `json:"description"`
+	Source      SourceLocation               `json:"source,omitempty"`
+	Resolution  *ContainerNameResolutionInfo `json:"resolution,omitempty"`
+}
+
+// ContainerNameResolutionInfo は container_name 衝突の解決情報を表します。
+type ContainerNameResolutionInfo struct {
+	ResolvedName string `json:"resolved_name"`
+	Reason       string `json:"reason"`
+}
+
+// VolumeConflictInfo はプロジェクト間で共有されるボリュームの衝突情報を表します。
+type VolumeConflictInfo struct {
+	// VolumeName はComposeファイル上のボリュームのキー（バインドマウントの場合はホストパス）です。
+	VolumeName string `json:"volume_name"`
+	// ActualName はDocker上のボリューム名（バインドマウントの場合は解決後のホストパス）です。
+	ActualName      string             `json:"actual_name"`
+	Kind            VolumeConflictKind `json:"kind"`
+	Services        []string           `json:"services"`
+	ExistingProject string             `json:"existing_project,omitempty"`
+	ProjectName     string             `json:"project_name"`
+	Severity        Severity           `json:"severity"`
+	Description     string             `json:"description"`
+	Source          SourceLocation     `json:"source,omitempty"`
+	// Resolution はプロジェクト別のボリューム名で解決できる場合のみ設定されます。
+	Resolution *VolumeResolutionInfo `json:"resolution,omitempty"`
+}
+
+// VolumeConflictKind はボリューム衝突の種類を表します。
+type VolumeConflictKind string
+
+const (
+	// VolumeConflictKindNamed は name: で固定された名前付きボリュームの共有です（名前の付け替えで解決可能）。
+	VolumeConflictKindNamed VolumeConflictKind = "named"
+	// VolumeConflictKindExternal は external: true のボリュームの共有です（警告のみ）。
+	VolumeConflictKindExternal VolumeConflictKind = "external"
+	// VolumeConflictKindBind は同じ絶対パスへのバインドマウントです（警告のみ）。
+	VolumeConflictKindBind VolumeConflictKind = "bind"
+)
+
+// IsResolvable はoverrideで解決できる衝突かどうかを返します。
+func (v VolumeConflictInfo) IsResolvable() bool {
+	return v.Kind == VolumeConflictKindNamed
+}
+
+// VolumeResolutionInfo はボリューム衝突の解決情報を表します。
+type VolumeResolutionInfo struct {
+	ResolvedName string `json:"resolved_name"`
+	Reason       string `json:"reason"`
+}
+
 // NetworkConflictType はネットワーク衝突の種類を表します。
 type NetworkConflictType string
 
@@ -42,9 +122,12 @@ const (
 
 // PortResolutionInfo はポート衝突の解決情報を表します。
 type PortResolutionInfo struct {
-	ResolvedPort int                `json:"resolved_port"`
-	Strategy     ResolutionStrategy `json:"strategy"`
-	Reason       string             `json:"reason"`
+	ResolvedPort    int                `json:"resolved_port"`
+	ResolvedPortEnd int                `json:"resolved_port_end,omitempty"` // 範囲指定の場合の終端
+	Strategy        ResolutionStrategy `json:"strategy"`
+	Reason          string             `json:"reason"`
+	// AffectedServices はポートを変更したサービスに（間接的に）依存するサービスです（変更の影響範囲）。
+	AffectedServices []string `json:"affected_services,omitempty"`
 }
 
 // NetworkResolutionInfo はネットワーク衝突の解決情報を表します。
@@ -56,7 +139,18 @@ type NetworkResolutionInfo struct {
 
 // HasConflicts は衝突があるかどうかを確認します。
 func (u *UnifiedConflictInfo) HasConflicts() bool {
-	return len(u.PortConflicts) > 0 || len(u.NetworkConflicts) > 0
+	return len(u.PortConflicts) > 0 || len(u.NetworkConflicts) > 0 || len(u.ContainerNameConflicts) > 0 ||
+		u.HasResolvableVolumeConflicts()
+}
+
+// HasResolvableVolumeConflicts はoverrideで解決できるボリューム衝突があるかどうかを確認します。
+func (u *UnifiedConflictInfo) HasResolvableVolumeConflicts() bool {
+	for _, conflict := range u.VolumeConflicts {
+		if conflict.IsResolvable() {
+			return true
+		}
+	}
+	return false
 }
 
 // HasPortConflicts はポート衝突があるかどうかを確認します。
@@ -64,6 +158,11 @@ func (u *UnifiedConflictInfo) HasPortConflicts() bool {
 	return len(u.PortConflicts) > 0
 }
 
+// HasHostNetworkConflicts は解決できないホストネットワークの衝突があるかどうかを確認します。
+func (u *UnifiedConflictInfo) HasHostNetworkConflicts() bool {
+	return len(u.HostNetworkConflicts) > 0
+}
+
 // HasNetworkConflicts はネットワーク衝突があるかどうかを確認します。
 func (u *UnifiedConflictInfo) HasNetworkConflicts() bool {
 	return len(u.NetworkConflicts) > 0
@@ -1,7 +1,12 @@
 // Package types は、gopose で使用される基本的な型定義を提供します。
 package types
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // PortRange はポート範囲を表す構造体です。
 type PortRange struct {
@@ -15,6 +20,64 @@ type PortMapping struct {
 	Container int    `yaml:"container" json:"container"`
 	Protocol  string `yaml:"protocol" json:"protocol"`
 	HostIP    string `yaml:"host_ip" json:"host_ip"`
+	// HostEnd は published が範囲指定（例: "8000-8010"）の場合の終端ポートです。
+	HostEnd     int    `yaml:"host_end,omitempty" json:"host_end,omitempty"`
+	Mode        string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Name        string `yaml:"name,omitempty" json:"name,omitempty"`
+	AppProtocol string `yaml:"app_protocol,omitempty" json:"app_protocol,omitempty"`
+	// LongSyntax は元ファイルが長形式（target/published）で記述されていたかを表します。
+	LongSyntax bool `yaml:"-" json:"-"`
+	// Source はポートが定義されたファイルです（include/extends 解決後の定義元）。
+	Source SourceLocation `yaml:"-" json:"source,omitempty"`
+}
+
+// IsRange はホストポートが範囲指定かどうかを返します。
+func (m PortMapping) IsRange() bool {
+	return m.HostEnd > m.Host
+}
+
+// HostPorts はマッピングが使用するホストポートを返します。
+func (m PortMapping) HostPorts() []int {
+	if m.Host == 0 {
+		return nil
+	}
+	if !m.IsRange() {
+		return []int{m.Host}
+	}
+	ports := make([]int, 0, m.HostEnd-m.Host+1)
+	for port := m.Host; port <= m.HostEnd; port++ {
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+// Published は published の表記（"8080" や "8000-8010"）を返します。
+func (m PortMapping) Published() string {
+	if m.IsRange() {
+		return fmt.Sprintf("%d-%d", m.Host, m.HostEnd)
+	}
+	return strconv.Itoa(m.Host)
+}
+
+// ShortSyntax は短形式の表記（"127.0.0.1:8080:80/udp" や "9229"）を返します。
+// ホストIP・プロトコル・ホストポートの無いエントリも元の意味を保ったまま表記します。
+func (m PortMapping) ShortSyntax() string {
+	var builder strings.Builder
+	if m.Host != 0 {
+		if m.HostIP != "" {
+			if strings.Contains(m.HostIP, ":") && !strings.HasPrefix(m.HostIP, "[") {
+				builder.WriteString("[" + m.HostIP + "]:")
+			} else {
+				builder.WriteString(m.HostIP + ":")
+			}
+		}
+		builder.WriteString(m.Published() + ":")
+	}
+	builder.WriteString(strconv.Itoa(m.Container))
+	if m.Protocol != "" && m.Protocol != "tcp" {
+		builder.WriteString("/" + m.Protocol)
+	}
+	return builder.String()
 }
 
 // Conflict は検出されたポート衝突を表します。
@@ -39,6 +102,10 @@ const (
 	ConflictTypeServicePort  ConflictType = "service_port"
 	ConflictTypeReservedPort ConflictType = "reserved_port"
 	ConflictTypeOutOfRange   ConflictType = "out_of_range"
+	// ConflictTypeHostNetwork は network_mode: host のサービスによる衝突です（override で解決できません）。
+	ConflictTypeHostNetwork ConflictType = "host_network"
+	// ConflictTypeContainerName は container_name が既存コンテナと重複している衝突です。
+	ConflictTypeContainerName ConflictType = "container_name"
 )
 
 // Severity は衝突の重要度を表します。
@@ -81,14 +148,16 @@ const (
 
 // ConflictResolution は衝突解決の結果を表します。
 type ConflictResolution struct {
-	Service      string             `json:"service"`
-	ServiceName  string             `json:"service_name"` // エイリアス
-	OriginalPort int                `json:"original_port"`
-	ConflictPort int                `json:"conflict_port"` // エイリアス
-	ResolvedPort int                `json:"resolved_port"`
-	Strategy     ResolutionStrategy `json:"strategy"`
-	Reason       string             `json:"reason"`
-	Timestamp    time.Time          `json:"timestamp"`
+	Service      string             `yaml:"service" json:"service"`
+	ServiceName  string             `yaml:"-" json:"service_name"` // エイリアス
+	OriginalPort int                `yaml:"original_port" json:"original_port"`
+	ConflictPort int                `yaml:"-" json:"conflict_port"` // エイリアス
+	Protocol     string             `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+	HostIP       string             `yaml:"host_ip,omitempty" json:"host_ip,omitempty"`
+	ResolvedPort int                `yaml:"resolved_port" json:"resolved_port"`
+	Strategy     ResolutionStrategy `yaml:"strategy" json:"strategy"`
+	Reason       string             `yaml:"reason" json:"reason"`
+	Timestamp    time.Time          `yaml:"-" json:"timestamp"`
 }
 
 // SystemPortInfo はシステムポート情報を表します。